@@ -0,0 +1,383 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package alerts displays alerts sent by the Prometheus Alertmanager
+// webhook receiver.
+//
+// Firing alerts are held until they are resolved or their end time
+// passes. The alert of the highest severity is shown with its name and
+// position on the first line and its summary scrolling on the second;
+// Up and Down cycle through the remaining alerts. The backlight flashes
+// while a critical alert is shown.
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// DefaultAddr is the address used by the alerts command by default.
+const DefaultAddr = "127.0.0.1:9099"
+
+const tick = 250 * time.Millisecond
+
+const maxBodyLen = 1 << 20
+
+// severities ranks the values of the severity label; alerts with other
+// values rank lowest.
+var severities = map[string]int{
+	"critical": 4,
+	"error":    3,
+	"warning":  2,
+	"info":     1,
+}
+
+// Alert is an alert included in a webhook notification.
+type Alert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+// Message is the body of a webhook notification.
+type Message struct {
+	Version string  `json:"version"`
+	Status  string  `json:"status"`
+	Alerts  []Alert `json:"alerts"`
+}
+
+// key returns a string identifying a, preferring the fingerprint
+// assigned by Alertmanager.
+func (a *Alert) key() string {
+	if a.Fingerprint != "" {
+		return a.Fingerprint
+	}
+	names := make([]string, 0, len(a.Labels))
+	for name := range a.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%q,", name, a.Labels[name])
+	}
+	return b.String()
+}
+
+func (a *Alert) severity() int {
+	return severities[strings.ToLower(a.Labels["severity"])]
+}
+
+func (a *Alert) name() string {
+	if s := a.Labels["alertname"]; s != "" {
+		return s
+	}
+	return "Alert"
+}
+
+func (a *Alert) summary() string {
+	for _, name := range []string{"summary", "description", "message"} {
+		if s := a.Annotations[name]; s != "" {
+			return strings.Join(strings.Fields(s), " ")
+		}
+	}
+	return a.name()
+}
+
+// Server receives webhook notifications and displays active alerts.
+// Errors updating the display are logged to ErrorLog, or if nil, the
+// standard logger.
+type Server struct {
+	LCD      *smclcd.LCD
+	ErrorLog *log.Logger
+
+	mu      sync.Mutex
+	alerts  map[string]*Alert
+	cur     string // key of alert shown; empty selects the highest severity
+	changed bool
+	once    sync.Once
+
+	closeOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+
+	// render state
+	marquee *smclcd.Marquee
+	keys    *smclcd.KeyFilter
+	ticks   int
+	light   smclcd.Backlight
+	init    bool
+}
+
+func NewServer(l *smclcd.LCD) *Server {
+	return &Server{
+		LCD:     l,
+		alerts:  make(map[string]*Alert),
+		changed: true,
+		done:    make(chan struct{}),
+	}
+}
+
+// Serve accepts webhook notifications on ln until an error occurs.
+func (s *Server) Serve(ln net.Listener) error {
+	var err error
+	s.once.Do(func() {
+		s.marquee = s.LCD.NewMarquee(smclcd.Region{Y: 1, Width: smclcd.Columns})
+		if err = s.marquee.Start(); err != nil {
+			return
+		}
+		s.keys = smclcd.NewKeyFilter(s.LCD)
+		s.wg.Add(2)
+		go s.run()
+		go s.pumpKeys()
+	})
+	if err != nil {
+		return err
+	}
+	return http.Serve(ln, s)
+}
+
+// Close stops updating the display and reading key input, and waits for
+// them to finish. Listeners passed to Serve are not closed.
+func (s *Server) Close() error {
+	s.once.Do(func() {}) // keep Serve from starting after Close
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if s.marquee == nil {
+			return
+		}
+		s.keys.Close()
+		s.wg.Wait()
+		s.marquee.Stop()
+	})
+	return nil
+}
+
+// ServeHTTP handles a webhook notification. Cross-origin requests and
+// bodies other than JSON are refused, so that browsers cannot be used to
+// post notifications.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin request", http.StatusForbidden)
+		return
+	}
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+		return
+	}
+	var m Message
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyLen)).Decode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range m.Alerts {
+		a := &m.Alerts[i]
+		key := a.key()
+		if a.Status == "resolved" {
+			delete(s.alerts, key)
+		} else {
+			if _, ok := s.alerts[key]; !ok {
+				s.cur = "" // show new alerts by severity
+			}
+			s.alerts[key] = a
+		}
+	}
+	s.changed = true
+}
+
+// sameOrigin reports whether r was sent from a page served by this host
+// or by a client other than a browser, which does not set Origin.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func (s *Server) pumpKeys() {
+	defer s.wg.Done()
+	for {
+		key, err := s.keys.GetInput()
+		if err != nil {
+			return
+		}
+		if key.Event != smclcd.KeyPress {
+			continue
+		}
+		switch key.Code {
+		case smclcd.KeyUp:
+			s.flip(-1)
+		case smclcd.KeyDown:
+			s.flip(1)
+		}
+	}
+}
+
+// flip selects the alert n positions from the one shown.
+func (s *Server) flip(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.list()
+	if len(list) == 0 {
+		return
+	}
+	i := (s.index(list) + n + len(list)) % len(list)
+	s.cur = list[i].key()
+	s.changed = true
+}
+
+// list returns the active alerts ordered by descending severity, then by
+// start time.
+func (s *Server) list() []*Alert {
+	list := make([]*Alert, 0, len(s.alerts))
+	for _, a := range s.alerts {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.severity() != b.severity() {
+			return a.severity() > b.severity()
+		}
+		if !a.StartsAt.Equal(b.StartsAt) {
+			return a.StartsAt.Before(b.StartsAt)
+		}
+		return a.key() < b.key()
+	})
+	return list
+}
+
+// index returns the position of the alert shown in list.
+func (s *Server) index(list []*Alert) int {
+	for i, a := range list {
+		if a.key() == s.cur {
+			return i
+		}
+	}
+	return 0
+}
+
+// run updates the display every tick until closed. Errors are logged
+// once until the next successful update.
+func (s *Server) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	var failed bool
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		s.mu.Lock()
+		err := s.update()
+		s.mu.Unlock()
+		if err != nil && !failed {
+			s.logf("alerts: %v", err)
+		}
+		failed = err != nil
+	}
+}
+
+func (s *Server) logf(format string, v ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+func (s *Server) update() (err error) {
+	s.ticks++
+
+	// Alertmanager sets the end time of firing alerts to when they will
+	// be considered resolved if not notified again.
+	now := time.Now()
+	for key, a := range s.alerts {
+		if !a.EndsAt.IsZero() && a.EndsAt.Before(now) {
+			delete(s.alerts, key)
+			s.changed = true
+		}
+	}
+
+	var a *Alert
+	list := s.list()
+	if len(list) > 0 {
+		i := s.index(list)
+		a = list[i]
+		if s.changed {
+			pos := fmt.Sprintf(" %d/%d", i+1, len(list))
+			name := a.name()
+			if r, n := []rune(name), smclcd.Columns-len(pos); len(r) > n {
+				name = string(r[:n])
+			}
+			err = s.LCD.SetLine(0, fmt.Sprintf("%-*s%s", smclcd.Columns-len(pos), name, pos), smclcd.AlignLeft)
+			s.marquee.SetText(a.summary())
+		}
+	} else if s.changed {
+		err = s.LCD.SetLine(0, "No alerts", smclcd.AlignLeft)
+		s.marquee.SetText("")
+	}
+	if err != nil {
+		return
+	}
+	s.changed = false
+
+	var light = smclcd.BacklightOn
+	if a != nil && a.severity() == severities["critical"] && s.ticks%4 >= 2 {
+		light = smclcd.BacklightOff
+	}
+	if light != s.light || !s.init {
+		if err = s.LCD.SetBacklight(light); err != nil {
+			return
+		}
+		s.light = light
+	}
+	s.init = true
+	return
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package alerts
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+func TestAlertName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Disk", "Disk         1/1"},
+		{"TempératureÉlevée", "TemperatureE 1/1"},
+		{"温度温度温度温度温度温度温度", "???????????? 1/1"},
+	}
+	for _, tt := range tests {
+		d := fakehid.New(smclcd.ErrTimeout)
+		l := smclcd.New(d)
+		s := NewServer(l)
+		s.marquee = l.NewMarquee(smclcd.Region{Y: 1, Width: smclcd.Columns})
+
+		body := `{"alerts":[{"status":"firing","labels":{"alertname":"` + tt.name + `"}}]}`
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		s.ServeHTTP(w, r)
+		if w.Code != http.StatusOK && w.Code != http.StatusNoContent {
+			t.Fatalf("status %d", w.Code)
+		}
+		if err := s.update(); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(d.Written()); got != tt.want {
+			t.Errorf("%s: line 0 = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServeHTTPRefused(t *testing.T) {
+	tests := []struct {
+		method      string
+		origin      string
+		contentType string
+		want        int
+	}{
+		{http.MethodPost, "", "application/json", http.StatusOK},
+		{http.MethodPost, "http://example.com", "application/json; charset=utf-8", http.StatusOK},
+		{http.MethodGet, "", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "http://evil.example", "application/json", http.StatusForbidden},
+		{http.MethodPost, "", "", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "", "text/plain", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "http://evil.example", "application/x-www-form-urlencoded", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		s := NewServer(smclcd.New(fakehid.New(smclcd.ErrTimeout)))
+		body := `{"alerts":[{"status":"firing","labels":{"alertname":"Disk"}}]}`
+		r := httptest.NewRequest(tt.method, "http://example.com/", strings.NewReader(body))
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s origin %q type %q: status %d, want %d", tt.method, tt.origin, tt.contentType, w.Code, tt.want)
+		}
+		if n := len(s.alerts); (n > 0) != (tt.want == http.StatusOK) {
+			t.Errorf("%s origin %q type %q: %d alerts held", tt.method, tt.origin, tt.contentType, n)
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	d := fakehid.New(smclcd.ErrTimeout)
+	defer d.Close()
+	s := NewServer(smclcd.New(d))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- s.Serve(ln) }()
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.HasPrefix(d.Text(), "No alerts") {
+		if time.Now().After(deadline) {
+			t.Fatalf("display = %q, want No alerts", d.Text())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return")
+	}
+	ln.Close()
+	<-errc
+
+	// The display is no longer updated.
+	s.mu.Lock()
+	s.changed = true
+	s.mu.Unlock()
+	n := len(d.Written())
+	time.Sleep(2 * tick)
+	if written := d.Written(); len(written) != n {
+		t.Errorf("wrote %q after Close", written[n:])
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnimationFrame is a single step of an Animation. Text is drawn to
+// Region, or to the whole display if Region is nil; bytes less than
+// GlyphSlots in Text display custom glyphs. Glyphs are programmed into
+// their slots before drawing. If Text is empty and Region is nil, the
+// contents of the display are left unchanged. The frame is displayed for
+// Duration.
+type AnimationFrame struct {
+	Text      string
+	Region    *Region
+	Glyphs    map[int]Glyph
+	Cursor    *Cursor
+	Backlight *Backlight
+	Duration  time.Duration
+}
+
+// Animation is a sequence of frames, played once or repeated until
+// stopped if Loop is set.
+type Animation struct {
+	Frames []AnimationFrame
+	Loop   bool
+}
+
+func (l *LCD) drawAnimationFrame(f *AnimationFrame) (err error) {
+	for slot, g := range f.Glyphs {
+		if err = l.SetGlyph(slot, g); err != nil {
+			return
+		}
+	}
+	if f.Text != "" || f.Region != nil {
+		r := Region{Width: Columns, Height: Lines}
+		if f.Region != nil {
+			r = *f.Region
+		}
+		if err = l.setRegion(r, f.Text, glyphCells); err != nil {
+			return
+		}
+	}
+	if f.Cursor != nil {
+		if err = l.SetCursor(*f.Cursor); err != nil {
+			return
+		}
+	}
+	if f.Backlight != nil {
+		err = l.SetBacklight(*f.Backlight)
+	}
+	return
+}
+
+// Player plays animations in the background. Glyph slots are programmed
+// directly, so animations should not be played while other users of the
+// display hold allocated glyphs.
+type Player struct {
+	l  *LCD
+	mu sync.Mutex
+	pb *playback
+}
+
+type playback struct {
+	stop chan struct{}
+	done chan struct{}
+	err  error
+}
+
+func (l *LCD) NewPlayer() *Player {
+	return &Player{l: l}
+}
+
+// Play stops the animation in progress, if any, and begins playing a.
+func (p *Player) Play(a *Animation) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stop()
+	p.pb = &playback{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go p.run(a, p.pb)
+}
+
+// Stop stops the animation in progress and waits for it to finish.
+func (p *Player) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stop()
+}
+
+func (p *Player) stop() {
+	if p.pb == nil {
+		return
+	}
+	select {
+	case <-p.pb.stop:
+	default:
+		close(p.pb.stop)
+	}
+	<-p.pb.done
+}
+
+// Wait waits for the animation in progress to finish and returns the
+// first error encountered updating the display. Looping animations
+// finish only when stopped.
+func (p *Player) Wait() error {
+	p.mu.Lock()
+	pb := p.pb
+	p.mu.Unlock()
+
+	if pb == nil {
+		return nil
+	}
+	<-pb.done
+	return pb.err
+}
+
+func (p *Player) run(a *Animation, pb *playback) {
+	defer close(pb.done)
+
+	for {
+		for i := range a.Frames {
+			f := &a.Frames[i]
+			if pb.err = p.l.drawAnimationFrame(f); pb.err != nil {
+				return
+			}
+			timer := time.NewTimer(f.Duration)
+			select {
+			case <-pb.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if !a.Loop || len(a.Frames) == 0 {
+			return
+		}
+	}
+}
+
+// ReadAnimation parses an animation in text format. Lines beginning with
+// '@' are directives; all other lines are the text of the current frame:
+//
+//	@loop                     repeat the animation until stopped
+//	@frame duration           begin a frame displayed for duration
+//	@region y x width height [left|center|right]
+//	@glyph slot row...        define a glyph using rows of '.' and '#'
+//	@cursor off|block|underline|both
+//	@backlight off|on
+//	@# comment
+//
+// Directives other than @loop apply to the current frame. In frame text,
+// \0 through \7 display custom glyphs and \\ is a backslash, which is
+// drawn as '/' as the character ROM lacks one; lines beginning with "@@"
+// begin with a literal '@'.
+func ReadAnimation(r io.Reader) (*Animation, error) {
+	var a Animation
+	var f *AnimationFrame
+	var text []string
+	flush := func() {
+		if f == nil {
+			return
+		}
+		f.Text = strings.Join(text, "\n")
+		if len(text) > 0 && f.Text == strings.Repeat("\n", len(text)-1) && f.Region == nil {
+			f.Region = &Region{Width: Columns, Height: Lines}
+		}
+		text = nil
+	}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if !strings.HasPrefix(line, "@") || strings.HasPrefix(line, "@@") {
+			if f == nil {
+				return nil, fmt.Errorf("anim: line %d: text outside of frame", n)
+			}
+			if strings.HasPrefix(line, "@@") {
+				line = line[1:]
+			}
+			t, err := unescapeText(line)
+			if err != nil {
+				return nil, fmt.Errorf("anim: line %d: %v", n, err)
+			}
+			text = append(text, t)
+			continue
+		}
+
+		fields := strings.Fields(line[1:])
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == "loop" {
+			a.Loop = true
+			continue
+		}
+		if fields[0] == "frame" {
+			flush()
+			a.Frames = append(a.Frames, AnimationFrame{})
+			f = &a.Frames[len(a.Frames)-1]
+		} else if f == nil {
+			return nil, fmt.Errorf("anim: line %d: %s outside of frame", n, fields[0])
+		}
+		if err := parseDirective(f, fields); err != nil {
+			return nil, fmt.Errorf("anim: line %d: %v", n, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return &a, nil
+}
+
+func parseDirective(f *AnimationFrame, fields []string) (err error) {
+	args := fields[1:]
+	switch fields[0] {
+	case "frame":
+		if len(args) != 1 {
+			return errors.New("frame: missing duration")
+		}
+		f.Duration, err = time.ParseDuration(args[0])
+	case "region":
+		var r Region
+		if len(args) < 4 || len(args) > 5 {
+			return errors.New("region: invalid arguments")
+		}
+		if err = scanInts(args, &r.Y, &r.X, &r.Width, &r.Height); err != nil {
+			return
+		}
+		if len(args) == 5 {
+			if r.Align, err = parseAlign(args[4]); err != nil {
+				return
+			}
+		}
+		if err = r.check(); err != nil {
+			return
+		}
+		f.Region = &r
+	case "glyph":
+		if len(args) < 1 || len(args) > 9 {
+			return errors.New("glyph: invalid arguments")
+		}
+		var slot int
+		if slot, err = strconv.Atoi(args[0]); err != nil || slot < 0 || slot >= GlyphSlots {
+			return errors.New("glyph: invalid slot")
+		}
+		for _, row := range args[1:] {
+			if len(row) != 5 || strings.Trim(row, ".#") != "" {
+				return errors.New("glyph: invalid row " + row)
+			}
+		}
+		g := mustGlyph(strings.Join(args[1:], " "))
+		if f.Glyphs == nil {
+			f.Glyphs = make(map[int]Glyph)
+		}
+		f.Glyphs[slot] = g
+	case "cursor":
+		var c Cursor
+		for c = CursorOff; c <= CursorBoth; c++ {
+			if len(args) == 1 && strings.EqualFold(args[0], c.String()) {
+				f.Cursor = &c
+				return
+			}
+		}
+		return errors.New("cursor: invalid argument")
+	case "backlight":
+		var b Backlight
+		for b = BacklightOff; b <= BacklightOn; b++ {
+			if len(args) == 1 && strings.EqualFold(args[0], b.String()) {
+				f.Backlight = &b
+				return
+			}
+		}
+		return errors.New("backlight: invalid argument")
+	default:
+		return errors.New("unknown directive " + fields[0])
+	}
+	return
+}
+
+func parseAlign(s string) (Align, error) {
+	for a := AlignLeft; a <= AlignRight; a++ {
+		if strings.EqualFold(s, a.String()) {
+			return a, nil
+		}
+	}
+	return 0, errors.New("invalid alignment " + s)
+}
+
+func unescapeText(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i++; i == len(s) {
+			return "", errors.New("trailing backslash")
+		}
+		switch c := s[i]; {
+		case c == '\\':
+			b.WriteByte(c)
+		case c >= '0' && c < '0'+GlyphSlots:
+			b.WriteByte(c - '0')
+		default:
+			return "", fmt.Errorf("invalid escape \\%c", c)
+		}
+	}
+	return b.String(), nil
+}
+
+// LoadAnimation reads an animation from the named file; see
+// ReadAnimation.
+func LoadAnimation(name string) (*Animation, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadAnimation(f)
+}
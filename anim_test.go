@@ -0,0 +1,214 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+func TestReadAnimation(t *testing.T) {
+	block, off := CursorBlock, BacklightOff
+	tests := []struct {
+		in   string
+		want Animation
+	}{
+		{
+			"@# comment\n@loop\n@frame 100ms\n@glyph 0 ..... ....# ...## #.##. ###.. .#...\nhello \\0\n@@world\n@cursor block\n@backlight off\n" +
+				"@frame 1s\n@region 1 2 4 1 right\na\\\\b\n",
+			Animation{
+				Frames: []AnimationFrame{
+					{
+						Text:      "hello \x00\n@world",
+						Glyphs:    map[int]Glyph{0: check},
+						Cursor:    &block,
+						Backlight: &off,
+						Duration:  100 * time.Millisecond,
+					},
+					{
+						Text:     "a\\b",
+						Region:   &Region{Y: 1, X: 2, Width: 4, Height: 1, Align: AlignRight},
+						Duration: time.Second,
+					},
+				},
+				Loop: true,
+			},
+		},
+		{
+			// A frame of blank lines clears the display; a frame
+			// without text leaves it unchanged.
+			"@frame 10ms\n\n@frame 10ms\n@\n",
+			Animation{
+				Frames: []AnimationFrame{
+					{Region: &Region{Width: Columns, Height: Lines}, Duration: 10 * time.Millisecond},
+					{Duration: 10 * time.Millisecond},
+				},
+			},
+		},
+		{"", Animation{}},
+	}
+	for _, tt := range tests {
+		a, err := ReadAnimation(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("ReadAnimation(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(*a, tt.want) {
+			t.Errorf("ReadAnimation(%q) = %+v, want %+v", tt.in, *a, tt.want)
+		}
+	}
+}
+
+func TestReadAnimationErrors(t *testing.T) {
+	const frame = "@frame 1s\n"
+	tests := []string{
+		"text\n",
+		"@cursor block\n",
+		"@bogus\n",
+		"@frame\n",
+		"@frame 1x\n",
+		"@frame 1s 2s\n",
+		frame + "@region 0 0 1\n",
+		frame + "@region a 0 1 1\n",
+		frame + "@region 0 0 17 1\n",
+		frame + "@region 0 0 1 1 middle\n",
+		frame + "@glyph\n",
+		frame + "@glyph 8\n",
+		frame + "@glyph x\n",
+		frame + "@glyph 0 ....\n",
+		frame + "@glyph 0 ..x..\n",
+		frame + "@glyph 0" + strings.Repeat(" .....", 9) + "\n",
+		frame + "@cursor blink\n",
+		frame + "@cursor\n",
+		frame + "@backlight dim\n",
+		frame + "trailing \\\n",
+		frame + "\\8\n",
+		frame + "\\x\n",
+	}
+	for _, in := range tests {
+		if _, err := ReadAnimation(strings.NewReader(in)); err == nil {
+			t.Errorf("ReadAnimation(%q) succeeded", in)
+		}
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	d := newFakeDevice()
+	defer d.Close()
+	p := New(d).NewPlayer()
+
+	underline, on := CursorUnderline, BacklightOn
+	p.Play(&Animation{
+		Frames: []AnimationFrame{
+			{Text: "\x00 hi", Glyphs: map[int]Glyph{0: check}, Duration: time.Millisecond},
+			{Text: "bye", Region: &Region{Y: 1, Width: Columns, Height: 1}},
+			{Cursor: &underline, Backlight: &on},
+		},
+	})
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\x00 hi            bye             "
+	if text := d.Text(); text != want {
+		t.Errorf("display = %q, want %q", text, want)
+	}
+	if g := d.Glyph(0); g != check {
+		t.Errorf("glyph 0 = %v, want %v", g, check)
+	}
+	if _, _, state := d.Cursor(); state != byte(CursorUnderline) {
+		t.Errorf("cursor = %d, want %d", state, CursorUnderline)
+	}
+	if !d.Backlight() {
+		t.Error("backlight off, want on")
+	}
+}
+
+func TestPlayerStop(t *testing.T) {
+	d := newFakeDevice()
+	defer d.Close()
+	p := New(d).NewPlayer()
+
+	// Stop and Wait return immediately when nothing was played.
+	p.Stop()
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	loop := func(text ...string) *Animation {
+		a := &Animation{Loop: true}
+		for _, s := range text {
+			a.Frames = append(a.Frames, AnimationFrame{Text: s, Duration: time.Millisecond})
+		}
+		return a
+	}
+	p.Play(loop("a", "b"))
+	time.Sleep(10 * time.Millisecond)
+
+	// Playing another animation stops the first.
+	p.Play(loop("c"))
+	time.Sleep(10 * time.Millisecond)
+	p.Stop()
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	n := len(d.Written())
+	if i := bytes.LastIndexAny(d.Written(), "ab"); i > bytes.IndexByte(d.Written(), 'c') {
+		t.Errorf("first animation drawn after second began: %q", d.Written())
+	}
+	time.Sleep(10 * time.Millisecond)
+	if written := d.Written(); len(written) != n {
+		t.Errorf("drew %q after Stop", written[n:])
+	}
+}
+
+// failingDevice fails all output reports.
+type failingDevice struct {
+	*fakehid.Device
+}
+
+func (d failingDevice) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestPlayerError(t *testing.T) {
+	d := failingDevice{newFakeDevice()}
+	defer d.Close()
+	p := New(d).NewPlayer()
+
+	p.Play(&Animation{
+		Frames: []AnimationFrame{{Text: "a"}, {Text: "b"}},
+		Loop:   true,
+	})
+	if err := p.Wait(); err == nil {
+		t.Error("Wait succeeded, want error")
+	}
+}
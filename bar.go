@@ -0,0 +1,239 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+const glyphFull = 0xff // full block in character ROM
+
+// ProgressBar draws a horizontal bar in a single-line region with a
+// resolution of five pixels per cell. If Label is set, the last four
+// columns of the region show the percentage complete.
+type ProgressBar struct {
+	Label bool
+
+	l     *LCD
+	r     Region
+	slots [4]byte
+}
+
+func (l *LCD) NewProgressBar(r Region) (b *ProgressBar, err error) {
+	r.Height = 1
+	if err = r.check(); err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b = &ProgressBar{l: l, r: r}
+	for i := range b.slots {
+		var g Glyph
+		for y := range g {
+			g[y] = 0x1f &^ (0x1f >> (i + 1))
+		}
+		if b.slots[i], err = l.allocGlyph(g); err != nil {
+			l.freeGlyphs(b.slots[:i])
+			return nil, err
+		}
+	}
+	return
+}
+
+// Set draws the bar filled to fraction, which is clamped to [0, 1]. NaN
+// is drawn as an empty bar.
+func (b *ProgressBar) Set(fraction float64) error {
+	if math.IsNaN(fraction) {
+		fraction = 0
+	}
+	fraction = math.Max(0, math.Min(1, fraction))
+
+	cells := bytes.Repeat([]byte(" "), b.r.Width)
+	width := b.r.Width
+	if b.Label && width > 4 {
+		width -= 4
+		copy(cells[width:], fmt.Sprintf("%3.0f%%", 100*fraction))
+	}
+	n := int(math.Round(fraction * float64(width*5)))
+	for i := 0; i < width && n > 0; i++ {
+		if n >= 5 {
+			cells[i] = glyphFull
+		} else {
+			cells[i] = b.slots[n-1]
+		}
+		n -= 5
+	}
+
+	b.l.mu.Lock()
+	defer b.l.mu.Unlock()
+	_, err := b.l.writeAt(cells, b.r.Y*Columns+b.r.X)
+	return err
+}
+
+// Close releases the glyphs used by the bar.
+func (b *ProgressBar) Close() {
+	b.l.mu.Lock()
+	defer b.l.mu.Unlock()
+	b.l.freeGlyphs(b.slots[:])
+}
+
+// Sparkline draws a vertical bar graph of samples in a single-line
+// region, one sample per column. Samples are scaled between Min and Max;
+// if both are zero, the range of the samples is used. Up to eight levels
+// of height are drawn; fewer are used if glyph slots are in short supply.
+type Sparkline struct {
+	Min, Max float64
+
+	l      *LCD
+	r      Region
+	levels []byte // cells for heights 1 through 8
+	slots  []byte
+}
+
+// sparklineLevels lists the heights given glyphs, in order of preference.
+var sparklineLevels = [][]int{
+	{1, 2, 3, 4, 5, 6, 7},
+	{2, 4, 6},
+	{4},
+	{},
+}
+
+func (l *LCD) NewSparkline(r Region) (s *Sparkline, err error) {
+	r.Height = 1
+	if err = r.check(); err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s = &Sparkline{l: l, r: r}
+	for _, heights := range sparklineLevels {
+		if err = s.alloc(heights); err != ErrNoGlyphSlots {
+			return
+		}
+	}
+	return
+}
+
+func (s *Sparkline) alloc(heights []int) error {
+	var cells [8]byte
+	for _, h := range heights {
+		var g Glyph
+		for y := len(g) - h; y < len(g); y++ {
+			g[y] = 0x1f
+		}
+		slot, err := s.l.allocGlyph(g)
+		if err != nil {
+			s.l.freeGlyphs(s.slots)
+			s.slots = nil
+			return err
+		}
+		s.slots = append(s.slots, slot)
+		cells[h-1] = slot
+	}
+
+	// Heights without a glyph use the next lowest available level.
+	cells[7] = glyphFull
+	for h := 6; h >= 0; h-- {
+		if !s.has(h+1, heights) {
+			cells[h] = ' '
+			for lower := h - 1; lower >= 0; lower-- {
+				if s.has(lower+1, heights) {
+					cells[h] = cells[lower]
+					break
+				}
+			}
+		}
+	}
+	s.levels = cells[:]
+	return nil
+}
+
+func (s *Sparkline) has(h int, heights []int) bool {
+	for _, v := range heights {
+		if v == h {
+			return true
+		}
+	}
+	return false
+}
+
+// Set draws the last samples that fit within the region. Samples that
+// are NaN or infinite are left blank and do not affect the range.
+func (s *Sparkline) Set(samples []float64) error {
+	if len(samples) > s.r.Width {
+		samples = samples[len(samples)-s.r.Width:]
+	}
+
+	min, max := s.Min, s.Max
+	if min == 0 && max == 0 && len(samples) > 0 {
+		min, max = math.Inf(1), math.Inf(-1)
+		for _, v := range samples {
+			if finite(v) {
+				min, max = math.Min(min, v), math.Max(max, v)
+			}
+		}
+	}
+
+	cells := bytes.Repeat([]byte(" "), s.r.Width)
+	for i, v := range samples {
+		if !finite(v) {
+			continue
+		}
+		h := 8
+		if max > min {
+			h = int(math.Round(8 * (v - min) / (max - min)))
+		}
+		if h = clamp(h, 0, 8); h > 0 {
+			cells[i] = s.levels[h-1]
+		}
+	}
+
+	s.l.mu.Lock()
+	defer s.l.mu.Unlock()
+	_, err := s.l.writeAt(cells, s.r.Y*Columns+s.r.X)
+	return err
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Close releases the glyphs used by the sparkline.
+func (s *Sparkline) Close() {
+	s.l.mu.Lock()
+	defer s.l.mu.Unlock()
+	s.l.freeGlyphs(s.slots)
+}
+
+func (l *LCD) freeGlyphs(slots []byte) {
+	for _, slot := range slots {
+		l.freeGlyph(slot)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"math"
+	"math/bits"
+	"strings"
+	"testing"
+
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+// bars returns the cells of a region, replacing a full block with '#'
+// and custom glyphs with the number of pixel columns they fill.
+func bars(d *fakehid.Device, r Region) string {
+	var b strings.Builder
+	for _, c := range []byte(d.Text()[r.Y*Columns+r.X:][:r.Width]) {
+		switch {
+		case c == glyphFull:
+			b.WriteByte('#')
+		case c < GlyphSlots:
+			g := d.Glyph(int(c))
+			b.WriteByte('0' + byte(bits.OnesCount8(g[0])))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// levels returns the cells of a region as the height of each column.
+func levels(d *fakehid.Device, r Region) string {
+	var b strings.Builder
+	for _, c := range []byte(d.Text()[r.Y*Columns+r.X:][:r.Width]) {
+		switch {
+		case c == glyphFull:
+			b.WriteByte('8')
+		case c < GlyphSlots:
+			n := byte(0)
+			for _, row := range d.Glyph(int(c)) {
+				if row == 0x1f {
+					n++
+				}
+			}
+			b.WriteByte('0' + n)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		label    bool
+		fraction float64
+		want     string
+	}{
+		{false, 0, "          "},
+		{false, 0.5, "#####     "},
+		{false, 0.52, "#####1    "},
+		{false, 0.56, "#####3    "},
+		{false, 1, "##########"},
+		{false, 1.5, "##########"},
+		{false, -1, "          "},
+		{false, math.Inf(1), "##########"},
+		{false, math.NaN(), "          "},
+		{true, 0.5, "###    50%"},
+		{true, 0.25, "#3     25%"},
+		{true, math.NaN(), "        0%"},
+	}
+	r := Region{Y: 1, X: 2, Width: 10}
+	for _, tt := range tests {
+		d := newFakeDevice()
+		b, err := New(d).NewProgressBar(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Label = tt.label
+		if err := b.Set(tt.fraction); err != nil {
+			t.Fatal(err)
+		}
+		if got := bars(d, r); got != tt.want {
+			t.Errorf("Set(%v) label %v = %q, want %q", tt.fraction, tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	tests := []struct {
+		min, max float64
+		samples  []float64
+		want     string
+	}{
+		{0, 8, []float64{0, 1, 2, 3, 4, 5, 6, 7}, " 1234567"},
+		{0, 8, []float64{-1, 8, 9}, " 88     "},
+		{0, 0, []float64{10, 20, 30}, " 48     "},
+		{0, 0, []float64{5, 5}, "88      "},
+		{0, 8, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8}, "12345678"},
+		{0, 0, []float64{nan, 4, inf, 8, -inf, 0}, " 4 8    "},
+		{0, 8, []float64{nan, inf, -inf, 4}, "   4    "},
+		{0, 0, []float64{nan, nan}, "        "},
+		{0, 0, nil, "        "},
+	}
+	r := Region{Y: 0, X: 4, Width: 8}
+	for _, tt := range tests {
+		d := newFakeDevice()
+		s, err := New(d).NewSparkline(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Min, s.Max = tt.min, tt.max
+		if err := s.Set(tt.samples); err != nil {
+			t.Fatal(err)
+		}
+		if got := levels(d, r); got != tt.want {
+			t.Errorf("Set(%v) in [%v, %v] = %q, want %q", tt.samples, tt.min, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestSparklineLevels(t *testing.T) {
+	tests := []struct {
+		used int
+		want string
+	}{
+		{0, "12345678"},
+		{1, "12345678"},
+		{2, " 2244668"},
+		{5, " 2244668"},
+		{6, "   44448"},
+		{7, "   44448"},
+		{8, "       8"},
+	}
+	r := Region{Width: 8}
+	for _, tt := range tests {
+		d := newFakeDevice()
+		l := New(d)
+		for i := 0; i < tt.used; i++ {
+			if _, err := l.AllocGlyph(Glyph{byte(i + 1)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s, err := l.NewSparkline(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Min, s.Max = 0, 8
+		if err := s.Set([]float64{1, 2, 3, 4, 5, 6, 7, 8}); err != nil {
+			t.Fatal(err)
+		}
+		if got := levels(d, r); got != tt.want {
+			t.Errorf("%d slots used = %q, want %q", tt.used, got, tt.want)
+		}
+
+		s.Close()
+		held := 0
+		for _, g := range l.glyphs {
+			if g.refs > 0 {
+				held++
+			}
+		}
+		if held != tt.used {
+			t.Errorf("%d slots used: %d slots held after Close", tt.used, held)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"errors"
+)
+
+// Glyphs used to draw big numbers. Each glyph is combined with blank and
+// full cells to draw characters spanning both lines of the display.
+var bigGlyphs = [...]Glyph{
+	{0x1f, 0x1f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // top bar
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f, 0x1f}, // bottom bar
+	{0x1f, 0x1f, 0x00, 0x00, 0x00, 0x00, 0x1f, 0x1f}, // top and bottom bars
+	{0x00, 0x00, 0x00, 0x0e, 0x0e, 0x00, 0x00, 0x00}, // dot
+}
+
+const (
+	bigT = iota + 0x10 // placeholders replaced with glyph slots
+	bigB
+	bigTB
+	bigDot
+	bigF = glyphFull
+)
+
+var bigChars = map[rune][2][]byte{
+	'0': {{bigF, bigT, bigF}, {bigF, bigB, bigF}},
+	'1': {{bigT, bigF, ' '}, {bigB, bigF, bigB}},
+	'2': {{bigTB, bigTB, bigF}, {bigF, bigB, bigB}},
+	'3': {{bigTB, bigTB, bigF}, {bigB, bigB, bigF}},
+	'4': {{bigF, bigB, bigF}, {' ', ' ', bigF}},
+	'5': {{bigF, bigTB, bigTB}, {bigB, bigB, bigF}},
+	'6': {{bigF, bigTB, bigTB}, {bigF, bigB, bigF}},
+	'7': {{bigT, bigT, bigF}, {' ', ' ', bigF}},
+	'8': {{bigF, bigTB, bigF}, {bigF, bigB, bigF}},
+	'9': {{bigF, bigTB, bigF}, {bigB, bigB, bigF}},
+	'-': {{bigB, bigB}, {' ', ' '}},
+	':': {{bigDot}, {bigDot}},
+	'.': {{' '}, {'.'}},
+	' ': {{' '}, {' '}},
+}
+
+func isBigDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// BigNumberWidth returns the number of columns needed to draw s using
+// BigNumber.
+func BigNumberWidth(s string) (n int) {
+	var prev rune
+	for _, r := range s {
+		if c, ok := bigChars[r]; ok {
+			if isBigDigit(prev) && isBigDigit(r) {
+				n++ // space between digits
+			}
+			n += len(c[0])
+		}
+		prev = r
+	}
+	return
+}
+
+// BigNumber draws s starting at column col using characters that span
+// both lines of the display. Digits, colons, minus signs, decimal points,
+// and spaces are supported; output is clipped to the display.
+//
+// The first call to BigNumber or RenderBigNumber reserves four glyph
+// slots. They are never released, even once no big numbers are shown,
+// and remain allocated until Close; only GlyphSlots-4 slots are left for
+// other glyphs, progress bars, and sparklines.
+func (l *LCD) BigNumber(col int, s string) (err error) {
+	if col < 0 || col >= Columns {
+		return errors.New("bignum: column out of bounds")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines, err := l.bigLines(col, s)
+	if err != nil {
+		return
+	}
+	for y, b := range lines {
+		if _, err = l.writeAt(b, y*Columns+col); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// RenderBigNumber draws s into f starting at column col like BigNumber,
+// but does not update the display. It reserves the same glyph slots.
+func (l *LCD) RenderBigNumber(f *Frame, col int, s string) error {
+	if col < 0 || col >= Columns {
+		return errors.New("bignum: column out of bounds")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines, err := l.bigLines(col, s)
+	if err != nil {
+		return err
+	}
+	for y, b := range lines {
+		copy(f.Line(y)[col:], b)
+	}
+	return nil
+}
+
+// bigLines returns the cells used to draw s at column col, loading the
+// big number glyphs if needed.
+func (l *LCD) bigLines(col int, s string) (lines [Lines][]byte, err error) {
+	if !l.bigLoaded {
+		var slots []byte
+		for _, g := range bigGlyphs {
+			var slot byte
+			if slot, err = l.allocGlyph(g); err != nil {
+				l.freeGlyphs(slots)
+				return
+			}
+			slots = append(slots, slot)
+		}
+		copy(l.big[:], slots)
+		l.bigLoaded = true
+	}
+
+	var prev rune
+	for _, r := range s {
+		c, ok := bigChars[r]
+		if !ok {
+			err = errors.New("bignum: unsupported character: " + string(r))
+			return
+		}
+		for y := range lines {
+			if isBigDigit(prev) && isBigDigit(r) {
+				lines[y] = append(lines[y], ' ')
+			}
+			for _, b := range c[y] {
+				if b >= bigT && b <= bigDot {
+					b = l.big[b-bigT]
+				}
+				lines[y] = append(lines[y], b)
+			}
+		}
+		prev = r
+	}
+	for y, b := range lines {
+		if len(b) > Columns-col {
+			lines[y] = b[:Columns-col]
+		}
+	}
+	return
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"strings"
+	"testing"
+)
+
+// big replaces the cells in b used by big numbers with a character
+// naming the glyph: '#' for a full block, and 'T', 'B', '=', and 'o' for
+// the top bar, bottom bar, both bars, and dot glyphs.
+func big(b []byte, glyph func(slot byte) Glyph) string {
+	var s strings.Builder
+	for _, c := range b {
+		switch {
+		case c == glyphFull:
+			s.WriteByte('#')
+		case c < GlyphSlots:
+			ch := byte('?')
+			for i, g := range bigGlyphs {
+				if glyph(c) == g {
+					ch = "TB=o"[i]
+				}
+			}
+			s.WriteByte(ch)
+		default:
+			s.WriteByte(c)
+		}
+	}
+	return s.String()
+}
+
+var bigNumberTests = []struct {
+	col  int
+	s    string
+	want [Lines]string
+}{
+	{0, "12:5", [Lines]string{
+		"T#  ==#o#==     ",
+		"B#B #BBoBB#     ",
+	}},
+	{3, "-1.5", [Lines]string{
+		"   BBT#  #==    ",
+		"     B#B.BB#    ",
+	}},
+	{5, "0 7", [Lines]string{
+		"     #T# TT#    ",
+		"     #B#   #    ",
+	}},
+	{14, "88", [Lines]string{
+		"              #=",
+		"              #B",
+	}},
+}
+
+func TestBigNumber(t *testing.T) {
+	for _, tt := range bigNumberTests {
+		d := newFakeDevice()
+		l := New(d)
+		if err := l.BigNumber(tt.col, tt.s); err != nil {
+			t.Fatal(err)
+		}
+		text := d.Text()
+		for y, want := range tt.want {
+			got := big([]byte(text[y*Columns:][:Columns]), func(slot byte) Glyph {
+				return d.Glyph(int(slot))
+			})
+			if got != want {
+				t.Errorf("BigNumber(%d, %q) line %d = %q, want %q", tt.col, tt.s, y, got, want)
+			}
+		}
+	}
+}
+
+func TestRenderBigNumber(t *testing.T) {
+	for _, tt := range bigNumberTests {
+		l := New(newFakeDevice())
+		f := NewFrame("")
+		if err := l.RenderBigNumber(&f, tt.col, tt.s); err != nil {
+			t.Fatal(err)
+		}
+		for y, want := range tt.want {
+			got := big(f.Line(y), func(slot byte) Glyph {
+				return l.glyphs[slot].g
+			})
+			if got != want {
+				t.Errorf("RenderBigNumber(%d, %q) line %d = %q, want %q", tt.col, tt.s, y, got, want)
+			}
+		}
+	}
+}
+
+func TestBigNumberWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"1", 3},
+		{"12", 7},
+		{"12:30", 15},
+		{"-1.5", 9},
+		{"1 2", 7},
+		{"1x2", 6},
+	}
+	for _, tt := range tests {
+		if n := BigNumberWidth(tt.s); n != tt.want {
+			t.Errorf("BigNumberWidth(%q) = %d, want %d", tt.s, n, tt.want)
+		}
+	}
+}
+
+func TestBigNumberErrors(t *testing.T) {
+	l := New(newFakeDevice())
+	for _, col := range []int{-1, Columns} {
+		if err := l.BigNumber(col, "1"); err == nil {
+			t.Errorf("BigNumber(%d, \"1\") succeeded, want error", col)
+		}
+		f := NewFrame("")
+		if err := l.RenderBigNumber(&f, col, "1"); err == nil {
+			t.Errorf("RenderBigNumber(%d, \"1\") succeeded, want error", col)
+		}
+	}
+	if err := l.BigNumber(0, "1a"); err == nil {
+		t.Error("BigNumber(0, \"1a\") succeeded, want error")
+	}
+}
+
+func TestBigNumberSlots(t *testing.T) {
+	l := New(newFakeDevice())
+	held := func() (slots []byte) {
+		for i, g := range l.glyphs {
+			if g.refs > 0 {
+				slots = append(slots, byte(i))
+			}
+		}
+		return
+	}
+
+	if err := l.BigNumber(0, "1"); err != nil {
+		t.Fatal(err)
+	}
+	want := held()
+	if len(want) != len(bigGlyphs) {
+		t.Fatalf("BigNumber held %d slots, want %d", len(want), len(bigGlyphs))
+	}
+
+	// Later calls, including renders and blank numbers, reuse the
+	// same slots without releasing them.
+	f := NewFrame("")
+	if err := l.RenderBigNumber(&f, 0, "8:8"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.BigNumber(0, " "); err != nil {
+		t.Fatal(err)
+	}
+	if got := held(); string(got) != string(want) {
+		t.Errorf("slots held = %v, want %v", got, want)
+	}
+	for _, slot := range want {
+		if refs := l.glyphs[slot].refs; refs != 1 {
+			t.Errorf("slot %d refs = %d, want 1", slot, refs)
+		}
+	}
+}
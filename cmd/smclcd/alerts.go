@@ -0,0 +1,102 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"net"
+
+	"github.com/sstallion/go-smclcd/alerts"
+	"github.com/sstallion/go-tools/command"
+)
+
+type alertsCmd struct {
+	flags  *flag.FlagSet
+	listen string
+}
+
+func init() {
+	cmd := &alertsCmd{flags: flag.NewFlagSet("alerts", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.StringVar(&cmd.listen, "listen", alerts.DefaultAddr, "`address`")
+	command.Add(cmd)
+}
+
+func (cmd *alertsCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *alertsCmd) Description() string {
+	return "Display Alertmanager alerts"
+}
+
+func (cmd *alertsCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+Alerts are received from an Alertmanager webhook receiver configured
+with a URL of the form http://address/. The alert of the highest
+severity is shown first; Up and Down cycle through active alerts, and
+the backlight flashes while a critical alert is shown.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-listen address]
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *alertsCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) != 0 {
+		return command.ErrNArg
+	}
+	return nil
+}
+
+func (cmd *alertsCmd) Run() error {
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	ln, err := net.Listen("tcp", cmd.listen)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	s := alerts.NewServer(l)
+	defer s.Close()
+	return serveUntilInterrupted(ln, func() error {
+		return s.Serve(ln)
+	})
+}
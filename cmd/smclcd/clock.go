@@ -0,0 +1,116 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-tools/command"
+)
+
+type clockCmd struct {
+	flags  *flag.FlagSet
+	format string
+}
+
+func init() {
+	cmd := &clockCmd{flags: flag.NewFlagSet("clock", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.StringVar(&cmd.format, "format", "15:04", "`layout`")
+	command.Add(cmd)
+}
+
+func (cmd *clockCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *clockCmd) Description() string {
+	return "Display clock using big numbers"
+}
+
+func (cmd *clockCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+TODO.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-format layout]
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *clockCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) != 0 {
+		return command.ErrNArg
+	}
+	return nil
+}
+
+func (cmd *clockCmd) Run() error {
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	if err = l.Clear(); err != nil {
+		return err
+	}
+	if err = l.SetCursor(smclcd.CursorOff); err != nil {
+		return err
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		s := time.Now().Format(cmd.format)
+		col := (smclcd.Columns - smclcd.BigNumberWidth(s)) / 2
+		if col < 0 {
+			col = 0
+		}
+		if err = l.BigNumber(col, s); err != nil {
+			return err
+		}
+		select {
+		case <-c:
+			return l.Clear()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/sstallion/go-smclcd/daemon"
+	"github.com/sstallion/go-tools/command"
+)
+
+const socketMode = 0660
+
+type daemonCmd struct {
+	flags  *flag.FlagSet
+	socket string
+}
+
+func init() {
+	cmd := &daemonCmd{flags: flag.NewFlagSet("daemon", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.StringVar(&cmd.socket, "socket", daemon.DefaultSocket, "`path`")
+	command.Add(cmd)
+}
+
+func (cmd *daemonCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *daemonCmd) Description() string {
+	return "Serve display over Unix domain socket"
+}
+
+func (cmd *daemonCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+TODO.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-socket path]
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *daemonCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) != 0 {
+		return command.ErrNArg
+	}
+	return nil
+}
+
+func (cmd *daemonCmd) Run() error {
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	// Remove stale socket left behind by an unclean exit, unless another
+	// daemon is still listening.
+	if fi, err := os.Lstat(cmd.socket); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if c, err := net.Dial("unix", cmd.socket); err == nil {
+			c.Close()
+			return fmt.Errorf("daemon already listening on %s", cmd.socket)
+		}
+		os.Remove(cmd.socket)
+	}
+	ln, err := net.Listen("unix", cmd.socket)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	// Restrict access to the owner and group of the socket.
+	if err = os.Chmod(cmd.socket, socketMode); err != nil {
+		return err
+	}
+
+	return serveUntilInterrupted(ln, func() error {
+		return daemon.NewServer(l).Serve(ln)
+	})
+}
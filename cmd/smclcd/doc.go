@@ -12,6 +12,8 @@ Global Flags:
 	-V	TODO
 	-debug
 	  	TODO
+	-key-file file
+	  	pre-shared key file for tcp:// paths
 	-path string
 	  	TODO
 	-serial string
@@ -19,13 +21,25 @@ Global Flags:
 
 Commands:
 
+	alerts        Display Alertmanager alerts
 	backlight     Backlight control
 	clear         Clear display
+	clock         Display clock using big numbers
 	cursor        Cursor control
+	daemon        Serve display over Unix domain socket
+	glyphs        Upload custom glyphs to display
 	home          Move cursor to home position
 	input         Print input events
+	lcdproc       Serve display to LCDproc clients
 	list          List compatible displays
+	marquee       Scroll text across display
+	mqtt          Bridge display to MQTT broker
+	pages         Rotate command output between pages
+	play          Play animation on display
 	read          Read from display
+	relay         Relay display reports over TCP
+	serve         Serve display over HTTP
+	syslog        Display syslog messages
 	version       Print display version
 	watch         Write periodic command output to display
 	write         Write to display
@@ -33,6 +47,24 @@ Commands:
 Use "smclcd help <command>" for more information about that command.
 Report issues to https://github.com/sstallion/go-smclcd/issues.
 
+# Display Alertmanager alerts
+
+Alerts are received from an Alertmanager webhook receiver configured
+with a URL of the form http://address/. The alert of the highest
+severity is shown first; Up and Down cycle through active alerts, and
+the backlight flashes while a critical alert is shown.
+
+Usage:
+
+	smclcd [global flags] alerts [-listen address]
+
+Flags:
+
+	-listen address
+	  	address (default "127.0.0.1:9099")
+
+Use "smclcd help" for more information about global flags.
+
 # Backlight control
 
 TODO.
@@ -53,6 +85,21 @@ Usage:
 
 Use "smclcd help" for more information about global flags.
 
+# Display clock using big numbers
+
+TODO.
+
+Usage:
+
+	smclcd [global flags] clock [-format layout]
+
+Flags:
+
+	-format layout
+	  	layout (default "15:04")
+
+Use "smclcd help" for more information about global flags.
+
 # Cursor control
 
 TODO.
@@ -63,6 +110,42 @@ Usage:
 
 Use "smclcd help" for more information about global flags.
 
+# Serve display over Unix domain socket
+
+TODO.
+
+Usage:
+
+	smclcd [global flags] daemon [-socket path]
+
+Flags:
+
+	-socket path
+	  	path (default "/run/smclcd.sock")
+
+Use "smclcd help" for more information about global flags.
+
+# Upload custom glyphs to display
+
+Glyphs are read from a BDF font if the file name ends in .bdf, otherwise
+from a text file where each glyph is introduced by a "char" line followed
+by up to eight rows of five '.' or '#' pixels. Glyphs are uploaded in
+order of code point starting at the first slot, and the slot assigned to
+each glyph is printed.
+
+Usage:
+
+	smclcd [global flags] glyphs [-show] [-slot slot] <file>
+
+Flags:
+
+	-show
+	  	display uploaded glyphs
+	-slot slot
+	  	first slot
+
+Use "smclcd help" for more information about global flags.
+
 # Move cursor to home position
 
 TODO.
@@ -79,7 +162,27 @@ TODO.
 
 Usage:
 
-	smclcd [global flags] input
+	smclcd [global flags] input [-gestures]
+
+Flags:
+
+	-gestures
+	  	TODO
+
+Use "smclcd help" for more information about global flags.
+
+# Serve display to LCDproc clients
+
+TODO.
+
+Usage:
+
+	smclcd [global flags] lcdproc [-listen address]
+
+Flags:
+
+	-listen address
+	  	address (default "127.0.0.1:13666")
 
 Use "smclcd help" for more information about global flags.
 
@@ -93,6 +196,98 @@ Usage:
 
 Use "smclcd help" for more information about global flags.
 
+# Scroll text across display
+
+TODO.
+
+Usage:
+
+	smclcd [global flags] marquee [flags] arguments...
+
+Flags:
+
+	-bounce
+	  	TODO
+	-pause duration
+	  	duration (default 2s)
+	-speed interval
+	  	interval (default 300ms)
+	-y line
+	  	line
+
+Use "smclcd help" for more information about global flags.
+
+# Bridge display to MQTT broker
+
+Key events are published to prefix/key/name as "press" or "release", and
+the backlight state to prefix/backlight as "ON" or "OFF". Line text is
+set by publishing to prefix/line/0/set and prefix/line/1/set, the
+display is cleared by publishing to prefix/clear/set, and the backlight
+is set by publishing "ON" or "OFF" to prefix/backlight/set. The text of
+each line is retained at prefix/line/0 and prefix/line/1. The retained
+message at prefix/status is "online" while connected and "offline"
+otherwise. If a discovery prefix such as homeassistant is given, Home
+Assistant discovery config is published so the display appears as a
+device.
+
+Usage:
+
+	smclcd [global flags] mqtt [-broker address] [-prefix topic] [-username username]
+	  [-password-file file] [-discovery prefix]
+
+Flags:
+
+	-broker address
+	  	broker address (default "localhost:1883")
+	-discovery prefix
+	  	Home Assistant discovery prefix
+	-password-file file
+	  	broker password file
+	-prefix topic
+	  	topic prefix (default "smclcd")
+	-username username
+	  	broker username
+
+Use "smclcd help" for more information about global flags.
+
+# Rotate command output between pages
+
+TODO.
+
+Usage:
+
+	smclcd [global flags] pages [flags] <command>...
+
+Flags:
+
+	-n interval
+	  	interval (default 10s)
+	-pause duration
+	  	duration (default 1m0s)
+	-refresh interval
+	  	interval
+	-step interval
+	  	transition interval (default 50ms)
+	-transition effect
+	  	effect
+
+Use "smclcd help" for more information about global flags.
+
+# Play animation on display
+
+TODO.
+
+Usage:
+
+	smclcd [global flags] play [-loop] <file>
+
+Flags:
+
+	-loop
+	  	repeat animation until interrupted
+
+Use "smclcd help" for more information about global flags.
+
 # Read from display
 
 TODO.
@@ -112,6 +307,71 @@ Flags:
 
 Use "smclcd help" for more information about global flags.
 
+# Relay display reports over TCP
+
+Reports are relayed to a single client at a time; the display may be
+opened remotely using a path of the form tcp://host:port. If a key file
+is given, clients must authenticate using its contents by passing the
+same file to the -key-file global flag. A key file is required unless
+address is a loopback address.
+
+Usage:
+
+	smclcd [global flags] relay [-key-file file] -listen address
+
+Flags:
+
+	-key-file file
+	  	pre-shared key file
+	-listen address
+	  	address
+
+Use "smclcd help" for more information about global flags.
+
+# Serve display over HTTP
+
+TODO.
+
+Usage:
+
+	smclcd [global flags] serve [-http address] [-panel]
+
+Flags:
+
+	-http address
+	  	address (default "127.0.0.1:8080")
+	-panel
+	  	serve virtual front panel
+
+Use "smclcd help" for more information about global flags.
+
+# Display syslog messages
+
+Messages are received over UDP, or over a Unix datagram socket if the
+listen address contains a slash. The newest message is shown with its
+timestamp; Up and Down browse earlier messages. Severities and
+facilities may be given by name or number.
+
+Usage:
+
+	smclcd [global flags] syslog [-listen address] [-severity severity]
+	  [-facility facilities] [-match regexp] [-history number]
+
+Flags:
+
+	-facility facilities
+	  	comma-separated facilities shown
+	-history number
+	  	number of messages kept (default 10)
+	-listen address
+	  	address or socket path (default "127.0.0.1:514")
+	-match regexp
+	  	regexp matching messages shown
+	-severity severity
+	  	least important severity shown (default "err")
+
+Use "smclcd help" for more information about global flags.
+
 # Print display version
 
 TODO.
@@ -132,10 +392,18 @@ Usage:
 
 Flags:
 
+	-ansi
+	  	TODO
 	-backlight state
 	  	state
 	-n interval
 	  	interval (default 30s)
+	-step interval
+	  	transition interval (default 50ms)
+	-text mode
+	  	mode
+	-transition effect
+	  	effect
 
 Use "smclcd help" for more information about global flags.
 
@@ -145,12 +413,14 @@ TODO.
 
 Usage:
 
-	smclcd [global flags] write [-x col] [-y line] arguments...
+	smclcd [global flags] write [-text mode] [-x col] [-y line] arguments...
 
 Flags:
 
 	-clear
 	  	TODO
+	-text mode
+	  	mode
 	-x col
 	  	col
 	-y line
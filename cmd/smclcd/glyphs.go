@@ -0,0 +1,127 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-tools/command"
+)
+
+type glyphsCmd struct {
+	flags *flag.FlagSet
+	slot  int
+	show  bool
+	name  string
+}
+
+func init() {
+	cmd := &glyphsCmd{flags: flag.NewFlagSet("glyphs", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.BoolVar(&cmd.show, "show", false, "display uploaded glyphs")
+	cmd.flags.IntVar(&cmd.slot, "slot", 0, "first `slot`")
+	command.Add(cmd)
+}
+
+func (cmd *glyphsCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *glyphsCmd) Description() string {
+	return "Upload custom glyphs to display"
+}
+
+func (cmd *glyphsCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+Glyphs are read from a BDF font if the file name ends in .bdf, otherwise
+from a text file where each glyph is introduced by a "char" line followed
+by up to eight rows of five '.' or '#' pixels. Glyphs are uploaded in
+order of code point starting at the first slot, and the slot assigned to
+each glyph is printed.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-show] [-slot slot] <file>
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *glyphsCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) != 1 {
+		return command.ErrNArg
+	}
+	if cmd.slot < 0 || cmd.slot >= smclcd.GlyphSlots {
+		return errors.New("invalid slot")
+	}
+	cmd.name = args[0]
+	return nil
+}
+
+func (cmd *glyphsCmd) Run() error {
+	set, err := smclcd.LoadGlyphs(cmd.name)
+	if err != nil {
+		return err
+	}
+	var runes []rune
+	for r := range set {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	if len(runes) > smclcd.GlyphSlots-cmd.slot {
+		return fmt.Errorf("too many glyphs: %d", len(runes))
+	}
+
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	var cells []byte
+	for i, r := range runes {
+		slot := cmd.slot + i
+		if err = l.SetGlyph(slot, set[r]); err != nil {
+			return err
+		}
+		cells = append(cells, byte(slot))
+		fmt.Printf("%d\t%c\tU+%04X\n", slot, r, r)
+	}
+	if cmd.show {
+		_, err = l.WriteAt(cells, 0)
+	}
+	return err
+}
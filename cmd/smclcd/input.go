@@ -37,12 +37,14 @@ import (
 )
 
 type inputCmd struct {
-	flags *flag.FlagSet
+	flags    *flag.FlagSet
+	gestures bool
 }
 
 func init() {
 	cmd := &inputCmd{flags: flag.NewFlagSet("input", flag.ExitOnError)}
 	cmd.flags.Usage = cmd.Usage
+	cmd.flags.BoolVar(&cmd.gestures, "gestures", false, "TODO")
 	command.Add(cmd)
 }
 
@@ -60,7 +62,11 @@ TODO.
 
 Usage:
 
-  {{ .Program }} [global flags] {{ .Name }}
+  {{ .Program }} [global flags] {{ .Name }} [-gestures]
+
+Flags:
+
+  {{ call .PrintDefaults }}
 
 Use "{{ .Program }} help" for more information about global flags.
 `)
@@ -89,8 +95,12 @@ func (cmd *inputCmd) Run() (err error) {
 		return err
 	}
 
-	var key smclcd.Key
 	w := tabwriter.NewWriter(os.Stdout, 10, 0, 2, ' ', 0)
+	if cmd.gestures {
+		return cmd.printGestures(l, w, height)
+	}
+
+	var key smclcd.Key
 	for i := 0; ; i++ {
 		if i%(height-1) == 0 {
 			fmt.Fprintf(w, "Sequence\tKey Code\tKey Event\f")
@@ -101,3 +111,17 @@ func (cmd *inputCmd) Run() (err error) {
 		fmt.Fprintf(w, "%8d\t%s\t%s\f", i, key.Code, key.Event)
 	}
 }
+
+func (cmd *inputCmd) printGestures(l *smclcd.LCD, w *tabwriter.Writer, height int) (err error) {
+	var e smclcd.GestureEvent
+	g := smclcd.NewGestureReader(l)
+	for i := 0; ; i++ {
+		if i%(height-1) == 0 {
+			fmt.Fprintf(w, "Sequence\tGesture\tKeys\f")
+		}
+		if e, err = g.Next(); err != nil {
+			return
+		}
+		fmt.Fprintf(w, "%8d\t%s\t%s\f", i, e.Gesture, e.Keys|smclcd.Keys(e.Code))
+	}
+}
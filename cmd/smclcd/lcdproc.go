@@ -0,0 +1,97 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"net"
+
+	"github.com/sstallion/go-smclcd/lcdproc"
+	"github.com/sstallion/go-tools/command"
+)
+
+type lcdprocCmd struct {
+	flags  *flag.FlagSet
+	listen string
+}
+
+func init() {
+	cmd := &lcdprocCmd{flags: flag.NewFlagSet("lcdproc", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.StringVar(&cmd.listen, "listen", lcdproc.DefaultAddr, "`address`")
+	command.Add(cmd)
+}
+
+func (cmd *lcdprocCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *lcdprocCmd) Description() string {
+	return "Serve display to LCDproc clients"
+}
+
+func (cmd *lcdprocCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+TODO.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-listen address]
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *lcdprocCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) != 0 {
+		return command.ErrNArg
+	}
+	return nil
+}
+
+func (cmd *lcdprocCmd) Run() error {
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	ln, err := net.Listen("tcp", cmd.listen)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	return serveUntilInterrupted(ln, func() error {
+		return lcdproc.NewServer(l).Serve(ln)
+	})
+}
@@ -62,7 +62,7 @@ func (debugFlag) Set(s string) error {
 	return nil
 }
 
-var pathFlag, serialFlag string
+var pathFlag, serialFlag, keyFileFlag string
 
 func usage() {
 	command.PrintGlobalUsage(`
@@ -91,5 +91,6 @@ func main() {
 	flag.Var(debugFlag{}, "debug", "TODO")
 	flag.StringVar(&pathFlag, "path", "", "TODO")
 	flag.StringVar(&serialFlag, "serial", "", "TODO")
+	flag.StringVar(&keyFileFlag, "key-file", "", "pre-shared key `file` for tcp:// paths")
 	command.Parse()
 }
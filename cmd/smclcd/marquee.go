@@ -0,0 +1,115 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-tools/command"
+)
+
+type marqueeCmd struct {
+	flags  *flag.FlagSet
+	bounce bool
+	speed  time.Duration
+	pause  time.Duration
+	y      uint
+	args   []string
+}
+
+func init() {
+	cmd := &marqueeCmd{flags: flag.NewFlagSet("marquee", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.BoolVar(&cmd.bounce, "bounce", false, "TODO")
+	cmd.flags.DurationVar(&cmd.speed, "speed", 300*time.Millisecond, "`interval`")
+	cmd.flags.DurationVar(&cmd.pause, "pause", 2*time.Second, "`duration`")
+	cmd.flags.UintVar(&cmd.y, "y", 0, "`line`")
+	command.Add(cmd)
+}
+
+func (cmd *marqueeCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *marqueeCmd) Description() string {
+	return "Scroll text across display"
+}
+
+func (cmd *marqueeCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+TODO.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [flags] arguments...
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *marqueeCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) < 1 {
+		return command.ErrNArg
+	}
+	cmd.args = args
+	return nil
+}
+
+func (cmd *marqueeCmd) Run() error {
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	m := l.NewMarquee(smclcd.Region{Y: int(cmd.y), Width: smclcd.Columns})
+	if cmd.bounce {
+		m.Mode = smclcd.MarqueeBounce
+	}
+	m.Speed = cmd.speed
+	m.Pause = cmd.pause
+	m.SetText(strings.Join(cmd.args, " "))
+	if err = m.Start(); err != nil {
+		return err
+	}
+	defer m.Stop()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	<-c
+	return nil
+}
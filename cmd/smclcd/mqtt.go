@@ -0,0 +1,350 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/mqtt"
+	"github.com/sstallion/go-tools/command"
+	"github.com/sstallion/go-tools/util"
+)
+
+type mqttCmd struct {
+	flags        *flag.FlagSet
+	broker       string
+	prefix       string
+	username     string
+	passwordFile string
+	discovery    string
+}
+
+func init() {
+	cmd := &mqttCmd{flags: flag.NewFlagSet("mqtt", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.StringVar(&cmd.broker, "broker", "localhost:1883", "broker `address`")
+	cmd.flags.StringVar(&cmd.prefix, "prefix", "smclcd", "`topic` prefix")
+	cmd.flags.StringVar(&cmd.username, "username", "", "broker `username`")
+	cmd.flags.StringVar(&cmd.passwordFile, "password-file", "", "broker password `file`")
+	cmd.flags.StringVar(&cmd.discovery, "discovery", "", "Home Assistant discovery `prefix`")
+	command.Add(cmd)
+}
+
+func (cmd *mqttCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *mqttCmd) Description() string {
+	return "Bridge display to MQTT broker"
+}
+
+func (cmd *mqttCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+Key events are published to prefix/key/name as "press" or "release", and
+the backlight state to prefix/backlight as "ON" or "OFF". Line text is
+set by publishing to prefix/line/0/set and prefix/line/1/set, the
+display is cleared by publishing to prefix/clear/set, and the backlight
+is set by publishing "ON" or "OFF" to prefix/backlight/set. The text of
+each line is retained at prefix/line/0 and prefix/line/1. The retained
+message at prefix/status is "online" while connected and "offline"
+otherwise. If a discovery prefix such as homeassistant is given, Home
+Assistant discovery config is published so the display appears as a
+device.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-broker address] [-prefix topic] [-username username]
+    [-password-file file] [-discovery prefix]
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *mqttCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) != 0 {
+		return command.ErrNArg
+	}
+	cmd.prefix = strings.TrimSuffix(cmd.prefix, "/")
+	return nil
+}
+
+func (cmd *mqttCmd) Run() error {
+	var password string
+	if cmd.passwordFile != "" {
+		b, err := os.ReadFile(cmd.passwordFile)
+		if err != nil {
+			return err
+		}
+		password = string(bytes.TrimSpace(b))
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	b := &mqttBridge{l: l, prefix: cmd.prefix, id: nodeID(host, cmd.prefix)}
+	c, err := mqtt.Dial(cmd.broker, &mqtt.Options{
+		ClientID:  b.id,
+		Username:  cmd.username,
+		Password:  password,
+		KeepAlive: 30 * time.Second,
+		Will:      b.message("status", "offline", true),
+		Handler:   b.handle,
+	})
+	if err != nil {
+		return err
+	}
+	b.c = c
+	defer func() {
+		c.Publish(b.message("status", "offline", true))
+		c.Close()
+	}()
+
+	if err = c.Subscribe(b.topic("line/+/set"), b.topic("clear/set"),
+		b.topic("backlight/set")); err != nil {
+		return err
+	}
+	if cmd.discovery != "" {
+		if err = b.discover(cmd.discovery); err != nil {
+			return err
+		}
+	}
+	if err = b.publishBacklight(smclcd.BacklightOn); err != nil {
+		return err
+	}
+	if err = c.Publish(b.message("status", "online", true)); err != nil {
+		return err
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- b.publishKeys()
+	}()
+
+	select {
+	case <-interrupted():
+		return nil
+	case <-c.Done():
+		return c.Err()
+	case err = <-errc:
+		return err
+	}
+}
+
+// mqttBridge relays key events and commands between a display and an
+// MQTT broker.
+type mqttBridge struct {
+	l      *smclcd.LCD
+	c      mqttPublisher
+	prefix string
+	id     string
+}
+
+// mqttPublisher is the part of *mqtt.Client used by mqttBridge.
+type mqttPublisher interface {
+	Publish(m *mqtt.Message) error
+}
+
+// nodeID returns an identifier derived from host and prefix suitable for
+// use as a client ID and Home Assistant node ID. Including the host keeps
+// bridges on different hosts using the same prefix from taking over each
+// other's sessions.
+func nodeID(host, prefix string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, host+"_"+prefix)
+}
+
+func (b *mqttBridge) topic(name string) string {
+	return b.prefix + "/" + name
+}
+
+func (b *mqttBridge) message(name, payload string, retain bool) *mqtt.Message {
+	return &mqtt.Message{Topic: b.topic(name), Payload: []byte(payload), Retain: retain}
+}
+
+func (b *mqttBridge) publishKeys() error {
+	r := smclcd.NewKeyFilter(b.l)
+	for {
+		key, err := r.GetInput()
+		if err != nil {
+			return err
+		}
+		name := "key/" + strings.ToLower(key.Code.String())
+		event := strings.ToLower(key.Event.String())
+		if err = b.c.Publish(b.message(name, event, false)); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *mqttBridge) publishBacklight(state smclcd.Backlight) error {
+	return b.c.Publish(b.message("backlight", strings.ToUpper(state.String()), true))
+}
+
+func (b *mqttBridge) publishLine(n int, text string) error {
+	return b.c.Publish(b.message("line/"+strconv.Itoa(n), text, true))
+}
+
+func (b *mqttBridge) handle(m *mqtt.Message) {
+	var err error
+	payload := string(m.Payload)
+	switch name := strings.TrimPrefix(m.Topic, b.prefix+"/"); name {
+	case "clear/set":
+		if err = b.l.Clear(); err != nil {
+			break
+		}
+		for n := 0; n < smclcd.Lines && err == nil; n++ {
+			err = b.publishLine(n, "")
+		}
+	case "backlight/set":
+		var state smclcd.Backlight
+		switch strings.ToUpper(strings.TrimSpace(payload)) {
+		case "ON":
+			state = smclcd.BacklightOn
+		case "OFF":
+			state = smclcd.BacklightOff
+		default:
+			err = fmt.Errorf("invalid backlight state: %q", payload)
+		}
+		if err == nil {
+			if err = b.l.SetBacklight(state); err == nil {
+				err = b.publishBacklight(state)
+			}
+		}
+	default:
+		var n int
+		s := strings.TrimSuffix(strings.TrimPrefix(name, "line/"), "/set")
+		if n, err = strconv.Atoi(s); err != nil || n < 0 || n >= smclcd.Lines {
+			err = errors.New("invalid line: " + s)
+			break
+		}
+		if err = b.l.SetLine(n, payload, smclcd.AlignLeft); err == nil {
+			err = b.publishLine(n, payload)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s: %v\n", util.Program(), m.Topic, err)
+	}
+}
+
+// discover publishes Home Assistant discovery config for the display.
+// Each key is exposed as a device trigger for press and release events,
+// each line as a text entity, and the backlight as a switch.
+func (b *mqttBridge) discover(prefix string) error {
+	version, err := b.l.Version()
+	if err != nil {
+		return err
+	}
+	device := map[string]interface{}{
+		"identifiers":  []string{b.id},
+		"name":         b.prefix,
+		"manufacturer": "Supermicro",
+		"model":        "LCD Display",
+		"sw_version":   version,
+	}
+	availability := b.topic("status")
+
+	publish := func(component, object string, config map[string]interface{}) error {
+		config["device"] = device
+		if component != "device_automation" {
+			config["unique_id"] = b.id + "_" + object
+			config["availability_topic"] = availability
+		}
+		payload, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+		return b.c.Publish(&mqtt.Message{
+			Topic:   strings.Join([]string{prefix, component, b.id, object, "config"}, "/"),
+			Payload: payload,
+			Retain:  true,
+		})
+	}
+
+	for code := smclcd.KeyUp; code <= smclcd.KeyCancel; code++ {
+		name := strings.ToLower(code.String())
+		for _, event := range []smclcd.KeyEvent{smclcd.KeyPress, smclcd.KeyRelease} {
+			e := strings.ToLower(event.String())
+			if err = publish("device_automation", name+"_"+e, map[string]interface{}{
+				"automation_type": "trigger",
+				"topic":           b.topic("key/" + name),
+				"payload":         e,
+				"type":            "button_short_" + e,
+				"subtype":         name,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	for n := 0; n < smclcd.Lines; n++ {
+		s := strconv.Itoa(n)
+		if err = publish("text", "line"+s, map[string]interface{}{
+			"name":          "Line " + s,
+			"command_topic": b.topic("line/" + s + "/set"),
+			"state_topic":   b.topic("line/" + s),
+			"max":           smclcd.Columns,
+		}); err != nil {
+			return err
+		}
+	}
+	if err = publish("button", "clear", map[string]interface{}{
+		"name":          "Clear",
+		"command_topic": b.topic("clear/set"),
+	}); err != nil {
+		return err
+	}
+	return publish("switch", "backlight", map[string]interface{}{
+		"name":          "Backlight",
+		"command_topic": b.topic("backlight/set"),
+		"state_topic":   b.topic("backlight"),
+	})
+}
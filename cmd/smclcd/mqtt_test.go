@@ -0,0 +1,230 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+	"github.com/sstallion/go-smclcd/mqtt"
+)
+
+// mqttRecorder records published messages.
+type mqttRecorder struct {
+	mu   sync.Mutex
+	msgs []*mqtt.Message
+}
+
+func (r *mqttRecorder) Publish(m *mqtt.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, m)
+	return nil
+}
+
+// take returns the messages published since the last call.
+func (r *mqttRecorder) take() []*mqtt.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msgs := r.msgs
+	r.msgs = nil
+	return msgs
+}
+
+func newBridge() (*mqttBridge, *fakehid.Device, *mqttRecorder) {
+	d := fakehid.New(smclcd.ErrTimeout)
+	r := &mqttRecorder{}
+	b := &mqttBridge{l: smclcd.New(d), c: r, prefix: "lcd", id: nodeID("host", "lcd")}
+	return b, d, r
+}
+
+// checkMessages checks that msgs are, in order, the messages in want,
+// written as "topic=payload" with " (retained)" appended if retained.
+func checkMessages(t *testing.T, msgs []*mqtt.Message, want ...string) {
+	t.Helper()
+	var got []string
+	for _, m := range msgs {
+		s := m.Topic + "=" + string(m.Payload)
+		if m.Retain {
+			s += " (retained)"
+		}
+		got = append(got, s)
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("published:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNodeID(t *testing.T) {
+	tests := []struct {
+		host, prefix string
+		want         string
+	}{
+		{"host", "smclcd", "host_smclcd"},
+		{"host.example.com", "home/lcd", "host_example_com_home_lcd"},
+		{"höst", "a+b#", "h_st_a_b_"},
+	}
+	for _, tt := range tests {
+		if id := nodeID(tt.host, tt.prefix); id != tt.want {
+			t.Errorf("nodeID(%q, %q) = %q, want %q", tt.host, tt.prefix, id, tt.want)
+		}
+	}
+}
+
+func TestBridgeHandle(t *testing.T) {
+	b, d, r := newBridge()
+	defer d.Close()
+
+	tests := []struct {
+		topic, payload string
+		text           string
+		backlight      bool
+		want           []string
+	}{
+		{"lcd/line/0/set", "hello", "hello                           ", false,
+			[]string{"lcd/line/0=hello (retained)"}},
+		{"lcd/line/1/set", "world", "hello           world           ", false,
+			[]string{"lcd/line/1=world (retained)"}},
+		{"lcd/line/2/set", "nope", "hello           world           ", false, nil},
+		{"lcd/line/x/set", "nope", "hello           world           ", false, nil},
+		{"lcd/backlight/set", "on", "hello           world           ", true,
+			[]string{"lcd/backlight=ON (retained)"}},
+		{"lcd/backlight/set", "dim", "hello           world           ", true, nil},
+		{"lcd/backlight/set", "OFF\n", "hello           world           ", false,
+			[]string{"lcd/backlight=OFF (retained)"}},
+		{"lcd/clear/set", "", "                                ", false,
+			[]string{"lcd/line/0= (retained)", "lcd/line/1= (retained)"}},
+	}
+	for _, tt := range tests {
+		b.handle(&mqtt.Message{Topic: tt.topic, Payload: []byte(tt.payload)})
+		if text := d.Text(); text != tt.text {
+			t.Errorf("%s %q: display = %q, want %q", tt.topic, tt.payload, text, tt.text)
+		}
+		if d.Backlight() != tt.backlight {
+			t.Errorf("%s %q: backlight = %v, want %v", tt.topic, tt.payload, d.Backlight(), tt.backlight)
+		}
+		checkMessages(t, r.take(), tt.want...)
+	}
+}
+
+func TestBridgeKeys(t *testing.T) {
+	b, d, r := newBridge()
+	errc := make(chan error, 1)
+	go func() { errc <- b.publishKeys() }()
+
+	d.Key(byte(smclcd.KeyEnter), 1)
+	d.Key(byte(smclcd.KeyEnter), 0)
+	d.Key(byte(smclcd.KeyCancel), 1)
+	deadline := time.Now().Add(time.Second)
+	var msgs []*mqtt.Message
+	for len(msgs) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		msgs = append(msgs, r.take()...)
+	}
+	checkMessages(t, msgs, "lcd/key/enter=press", "lcd/key/enter=release", "lcd/key/cancel=press")
+
+	d.Close()
+	if err := <-errc; err == nil {
+		t.Error("publishKeys() succeeded after device closed")
+	}
+}
+
+func TestBridgeDiscover(t *testing.T) {
+	b, d, r := newBridge()
+	defer d.Close()
+
+	if err := b.discover("homeassistant"); err != nil {
+		t.Fatal(err)
+	}
+	configs := make(map[string]map[string]interface{})
+	for _, m := range r.take() {
+		if !m.Retain {
+			t.Errorf("%s not retained", m.Topic)
+		}
+		var config map[string]interface{}
+		if err := json.Unmarshal(m.Payload, &config); err != nil {
+			t.Fatalf("%s: %v", m.Topic, err)
+		}
+		configs[m.Topic] = config
+	}
+	if n, want := len(configs), 2*int(smclcd.KeyCancel+1)+smclcd.Lines+2; n != want {
+		t.Errorf("published %d configs, want %d", n, want)
+	}
+
+	tests := []struct {
+		topic string
+		want  map[string]interface{}
+	}{
+		{"homeassistant/device_automation/host_lcd/up_press/config", map[string]interface{}{
+			"automation_type": "trigger",
+			"topic":           "lcd/key/up",
+			"payload":         "press",
+			"type":            "button_short_press",
+			"subtype":         "up",
+		}},
+		{"homeassistant/device_automation/host_lcd/cancel_release/config", map[string]interface{}{
+			"topic":   "lcd/key/cancel",
+			"payload": "release",
+		}},
+		{"homeassistant/text/host_lcd/line1/config", map[string]interface{}{
+			"unique_id":          "host_lcd_line1",
+			"availability_topic": "lcd/status",
+			"command_topic":      "lcd/line/1/set",
+			"state_topic":        "lcd/line/1",
+			"max":                float64(smclcd.Columns),
+		}},
+		{"homeassistant/button/host_lcd/clear/config", map[string]interface{}{
+			"unique_id":     "host_lcd_clear",
+			"command_topic": "lcd/clear/set",
+		}},
+		{"homeassistant/switch/host_lcd/backlight/config", map[string]interface{}{
+			"command_topic": "lcd/backlight/set",
+			"state_topic":   "lcd/backlight",
+		}},
+	}
+	for _, tt := range tests {
+		config, ok := configs[tt.topic]
+		if !ok {
+			t.Errorf("%s not published", tt.topic)
+			continue
+		}
+		for k, v := range tt.want {
+			if config[k] != v {
+				t.Errorf("%s: %s = %v, want %v", tt.topic, k, config[k], v)
+			}
+		}
+		device, _ := config["device"].(map[string]interface{})
+		if device["sw_version"] != "1.0" || device["name"] != "lcd" {
+			t.Errorf("%s: device = %v", tt.topic, device)
+		}
+		if _, ok := config["unique_id"]; ok == strings.Contains(tt.topic, "device_automation") {
+			t.Errorf("%s: unique_id = %v", tt.topic, config["unique_id"])
+		}
+	}
+}
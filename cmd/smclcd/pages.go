@@ -0,0 +1,128 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/screen"
+	"github.com/sstallion/go-tools/command"
+)
+
+type pagesCmd struct {
+	flags   *flag.FlagSet
+	n       time.Duration
+	pause   time.Duration
+	refresh time.Duration
+	trans   smclcd.Transition
+	step    time.Duration
+	args    []string
+}
+
+func init() {
+	cmd := &pagesCmd{flags: flag.NewFlagSet("pages", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.DurationVar(&cmd.n, "n", 10*time.Second, "`interval`")
+	cmd.flags.DurationVar(&cmd.pause, "pause", time.Minute, "`duration`")
+	cmd.flags.DurationVar(&cmd.refresh, "refresh", 0, "`interval`")
+	cmd.flags.DurationVar(&cmd.step, "step", 50*time.Millisecond, "transition `interval`")
+	cmd.flags.Func("transition", "`effect`", cmd.parseTransition)
+	command.Add(cmd)
+}
+
+func (cmd *pagesCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *pagesCmd) Description() string {
+	return "Rotate command output between pages"
+}
+
+func (cmd *pagesCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+TODO.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [flags] <command>...
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *pagesCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) < 1 {
+		return command.ErrNArg
+	}
+	cmd.args = args
+	return nil
+}
+
+func (cmd *pagesCmd) parseTransition(s string) (err error) {
+	cmd.trans, err = parseTransition(s)
+	return
+}
+
+func (cmd *pagesCmd) Run() error {
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	m := screen.New(l, smclcd.NewGestureReader(smclcd.NewKeyFilter(l)))
+	m.Interval = cmd.n
+	m.Pause = cmd.pause
+	m.Transition = cmd.trans
+	m.Step = cmd.step
+	for _, arg := range cmd.args {
+		m.Pages = append(m.Pages, &screen.Page{
+			Name:    arg,
+			Render:  shellRender(arg),
+			Refresh: cmd.refresh,
+		})
+	}
+	return m.Run()
+}
+
+func shellRender(s string) func() (string, error) {
+	return func() (string, error) {
+		c := exec.Command("sh", "-c", s)
+		c.Stderr = os.Stderr
+		b, err := c.Output()
+		return string(b), err
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-tools/command"
+)
+
+type playCmd struct {
+	flags *flag.FlagSet
+	loop  bool
+	name  string
+}
+
+func init() {
+	cmd := &playCmd{flags: flag.NewFlagSet("play", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.BoolVar(&cmd.loop, "loop", false, "repeat animation until interrupted")
+	command.Add(cmd)
+}
+
+func (cmd *playCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *playCmd) Description() string {
+	return "Play animation on display"
+}
+
+func (cmd *playCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+TODO.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-loop] <file>
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *playCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) != 1 {
+		return command.ErrNArg
+	}
+	cmd.name = args[0]
+	return nil
+}
+
+func (cmd *playCmd) Run() error {
+	a, err := smclcd.LoadAnimation(cmd.name)
+	if err != nil {
+		return err
+	}
+	a.Loop = a.Loop || cmd.loop
+
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	p := l.NewPlayer()
+	p.Play(a)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Wait()
+	}()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	select {
+	case <-c:
+		p.Stop()
+		return l.Clear()
+	case err = <-done:
+		return err
+	}
+}
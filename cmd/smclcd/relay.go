@@ -0,0 +1,111 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"net"
+
+	"github.com/sstallion/go-smclcd/relay"
+	"github.com/sstallion/go-tools/command"
+)
+
+type relayCmd struct {
+	flags   *flag.FlagSet
+	listen  string
+	keyFile string
+}
+
+func init() {
+	cmd := &relayCmd{flags: flag.NewFlagSet("relay", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.StringVar(&cmd.keyFile, "key-file", "", "pre-shared key `file`")
+	cmd.flags.StringVar(&cmd.listen, "listen", "", "`address`")
+	command.Add(cmd)
+}
+
+func (cmd *relayCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *relayCmd) Description() string {
+	return "Relay display reports over TCP"
+}
+
+func (cmd *relayCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+Reports are relayed to a single client at a time; the display may be
+opened remotely using a path of the form tcp://host:port. If a key file
+is given, clients must authenticate using its contents by passing the
+same file to the -key-file global flag. A key file is required unless
+address is a loopback address.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-key-file file] -listen address
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *relayCmd) Parse(arguments []string) error {
+	if err := cmd.flags.Parse(arguments); err != nil {
+		return err
+	}
+	args := cmd.flags.Args()
+	if len(args) != 0 || cmd.listen == "" {
+		return command.ErrNArg
+	}
+	return nil
+}
+
+func (cmd *relayCmd) Run() error {
+	var key []byte
+	if cmd.keyFile != "" {
+		var err error
+		if key, err = relay.ReadKey(cmd.keyFile); err != nil {
+			return err
+		}
+	}
+
+	device, err := openDevice()
+	if err != nil {
+		return err
+	}
+	defer device.Close()
+
+	ln, err := net.Listen("tcp", cmd.listen)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	return serveUntilInterrupted(ln, func() error {
+		return relay.NewServer(device, key).Serve(ln)
+	})
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/sstallion/go-smclcd/syslog"
+	"github.com/sstallion/go-tools/command"
+)
+
+type syslogCmd struct {
+	flags      *flag.FlagSet
+	listen     string
+	severity   string
+	facility   string
+	match      string
+	history    uint
+	sev        int
+	facilities map[int]bool
+	pattern    *regexp.Regexp
+}
+
+func init() {
+	cmd := &syslogCmd{flags: flag.NewFlagSet("syslog", flag.ExitOnError)}
+	cmd.flags.Usage = cmd.Usage
+	cmd.flags.StringVar(&cmd.listen, "listen", syslog.DefaultAddr, "`address` or socket path")
+	cmd.flags.StringVar(&cmd.severity, "severity", "err", "least important `severity` shown")
+	cmd.flags.StringVar(&cmd.facility, "facility", "", "comma-separated `facilities` shown")
+	cmd.flags.StringVar(&cmd.match, "match", "", "`regexp` matching messages shown")
+	cmd.flags.UintVar(&cmd.history, "history", 10, "`number` of messages kept")
+	command.Add(cmd)
+}
+
+func (cmd *syslogCmd) Name() string {
+	return cmd.flags.Name()
+}
+
+func (cmd *syslogCmd) Description() string {
+	return "Display syslog messages"
+}
+
+func (cmd *syslogCmd) Usage() {
+	command.PrintUsage(cmd.flags, `
+Messages are received over UDP, or over a Unix datagram socket if the
+listen address contains a slash. The newest message is shown with its
+timestamp; Up and Down browse earlier messages. Severities and
+facilities may be given by name or number.
+
+Usage:
+
+  {{ .Program }} [global flags] {{ .Name }} [-listen address] [-severity severity]
+    [-facility facilities] [-match regexp] [-history number]
+
+Flags:
+
+  {{ call .PrintDefaults }}
+
+Use "{{ .Program }} help" for more information about global flags.
+`)
+}
+
+func (cmd *syslogCmd) Parse(arguments []string) (err error) {
+	if err = cmd.flags.Parse(arguments); err != nil {
+		return
+	}
+	args := cmd.flags.Args()
+	if len(args) != 0 || cmd.history == 0 {
+		return command.ErrNArg
+	}
+	if cmd.sev, err = syslog.ParseSeverity(cmd.severity); err != nil {
+		return
+	}
+	if cmd.facility != "" {
+		cmd.facilities = make(map[int]bool)
+		for _, s := range strings.Split(cmd.facility, ",") {
+			var n int
+			if n, err = syslog.ParseFacility(s); err != nil {
+				return
+			}
+			cmd.facilities[n] = true
+		}
+	}
+	if cmd.match != "" {
+		cmd.pattern, err = regexp.Compile(cmd.match)
+	}
+	return
+}
+
+func (cmd *syslogCmd) Run() error {
+	l, err := openLCD()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	var conn net.PacketConn
+	if strings.Contains(cmd.listen, "/") {
+		if conn, err = listenUnixgram(cmd.listen); err != nil {
+			return err
+		}
+	} else if conn, err = net.ListenPacket("udp", cmd.listen); err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	srv := syslog.NewServer(l)
+	srv.Severity = cmd.sev
+	srv.Facilities = cmd.facilities
+	srv.Pattern = cmd.pattern
+	srv.History = int(cmd.history)
+	return serveUntilInterrupted(conn, func() error {
+		return srv.Serve(conn)
+	})
+}
+
+// unixgramConn removes its socket when closed, provided it has not since
+// been replaced.
+type unixgramConn struct {
+	net.PacketConn
+	path string
+	fi   os.FileInfo
+}
+
+// listenUnixgram listens on the datagram socket at path. A stale socket
+// left behind by an unclean exit is removed, unless another receiver is
+// still bound to it.
+func listenUnixgram(path string) (net.PacketConn, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if c, err := net.Dial("unixgram", path); err == nil {
+			c.Close()
+			return nil, fmt.Errorf("receiver already listening on %s", path)
+		}
+		os.Remove(path)
+	}
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &unixgramConn{conn, path, fi}, nil
+}
+
+func (c *unixgramConn) Close() error {
+	err := c.PacketConn.Close()
+	if fi, e := os.Lstat(c.path); e == nil && os.SameFile(fi, c.fi) {
+		os.Remove(c.path)
+	}
+	return err
+}
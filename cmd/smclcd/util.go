@@ -24,11 +24,29 @@
 package main
 
 import (
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/sstallion/go-hid"
 	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/relay"
 )
 
 func openLCD() (*smclcd.LCD, error) {
 	switch {
+	case strings.HasPrefix(pathFlag, "tcp://"):
+		var key []byte
+		if keyFileFlag != "" {
+			var err error
+			if key, err = relay.ReadKey(keyFileFlag); err != nil {
+				return nil, err
+			}
+		}
+		return relay.OpenURL(pathFlag, key)
 	case pathFlag != "":
 		return smclcd.OpenPath(pathFlag)
 	case serialFlag != "":
@@ -37,3 +55,87 @@ func openLCD() (*smclcd.LCD, error) {
 		return smclcd.OpenFirst()
 	}
 }
+
+func openDevice() (smclcd.Device, error) {
+	var device *hid.Device
+	var err error
+	switch {
+	case pathFlag != "":
+		device, err = hid.OpenPath(pathFlag)
+	case serialFlag != "":
+		device, err = hid.Open(smclcd.VendorID, smclcd.ProductID, serialFlag)
+	default:
+		device, err = hid.OpenFirst(smclcd.VendorID, smclcd.ProductID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return smclcd.HIDDevice(device), nil
+}
+
+func parseTextMode(s string) (mode smclcd.TextMode, err error) {
+	for _, v := range strings.Split(s, ",") {
+		switch v {
+		case "wrap":
+			mode |= smclcd.TextWrap
+		case "control":
+			mode |= smclcd.TextControl
+		case "scroll":
+			mode |= smclcd.TextScroll
+		default:
+			return 0, errors.New("invalid argument: " + v)
+		}
+	}
+	return
+}
+
+var transitions = map[string]smclcd.Transition{
+	"none":        smclcd.TransitionNone,
+	"slide-left":  smclcd.TransitionSlideLeft,
+	"slide-right": smclcd.TransitionSlideRight,
+	"wipe":        smclcd.TransitionWipe,
+	"typewriter":  smclcd.TransitionTypewriter,
+	"scroll-up":   smclcd.TransitionScrollUp,
+}
+
+func parseTransition(s string) (smclcd.Transition, error) {
+	t, ok := transitions[s]
+	if !ok {
+		return 0, errors.New("invalid argument: " + s)
+	}
+	return t, nil
+}
+
+// interrupted returns a channel that receives interrupt and termination
+// signals.
+func interrupted() chan os.Signal {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	return c
+}
+
+// serveUntilInterrupted calls serve, closing c when interrupted. The error
+// returned by serve is discarded if it was caused by closing c.
+func serveUntilInterrupted(c io.Closer, serve func() error) error {
+	sig := interrupted()
+	defer signal.Stop(sig)
+	stopped := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-sig:
+			close(stopped)
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	err := serve()
+	select {
+	case <-stopped:
+		return nil
+	default:
+		return err
+	}
+}
@@ -65,10 +65,11 @@ func (b *watchBacklight) Run(l *smclcd.LCD) (err error) {
 			}
 		}()
 		go func() {
+			f := smclcd.NewKeyFilter(l)
 			for {
-				key, err := l.GetInput()
+				key, err := f.GetInput()
 				if err != nil {
-					continue
+					return
 				}
 				select {
 				case keys <- key:
@@ -83,8 +84,13 @@ func (b *watchBacklight) Run(l *smclcd.LCD) (err error) {
 
 type watchCmd struct {
 	flags     *flag.FlagSet
+	ansi      bool
 	n         time.Duration
 	backlight watchBacklight
+	mode      smclcd.TextMode
+	frames    bool
+	trans     smclcd.Transition
+	step      time.Duration
 	name      string
 	args      []string
 }
@@ -94,8 +100,12 @@ var ()
 func init() {
 	cmd := &watchCmd{flags: flag.NewFlagSet("watch", flag.ExitOnError)}
 	cmd.flags.Usage = cmd.Usage
+	cmd.flags.BoolVar(&cmd.ansi, "ansi", false, "TODO")
 	cmd.flags.Func("backlight", "`state`", cmd.parseBacklight)
 	cmd.flags.DurationVar(&cmd.n, "n", 30*time.Second, "`interval`")
+	cmd.flags.DurationVar(&cmd.step, "step", 50*time.Millisecond, "transition `interval`")
+	cmd.flags.Func("text", "`mode`", cmd.parseTextMode)
+	cmd.flags.Func("transition", "`effect`", cmd.parseTransition)
 	command.Add(cmd)
 }
 
@@ -133,7 +143,16 @@ func (cmd *watchCmd) Parse(arguments []string) error {
 	}
 	cmd.name = args[0]
 	cmd.args = args[1:]
-	return nil
+
+	// Transitions draw rendered frames, which bypass the text mode and
+	// terminal emulation.
+	var err error
+	cmd.flags.Visit(func(f *flag.Flag) {
+		if cmd.frames && (f.Name == "ansi" || f.Name == "text") {
+			err = errors.New("-transition cannot be used with -" + f.Name)
+		}
+	})
+	return err
 }
 
 func (cmd *watchCmd) parseBacklight(s string) error {
@@ -150,6 +169,17 @@ func (cmd *watchCmd) parseBacklight(s string) error {
 	return nil
 }
 
+func (cmd *watchCmd) parseTextMode(s string) (err error) {
+	cmd.mode, err = parseTextMode(s)
+	return
+}
+
+func (cmd *watchCmd) parseTransition(s string) (err error) {
+	cmd.trans, err = parseTransition(s)
+	cmd.frames = true
+	return
+}
+
 func (cmd *watchCmd) Run() error {
 	l, err := openLCD()
 	if err != nil {
@@ -160,8 +190,15 @@ func (cmd *watchCmd) Run() error {
 	if err = cmd.backlight.Run(l); err != nil {
 		return err
 	}
+	l.SetTextMode(cmd.mode)
+
+	var w io.Writer = l
+	if cmd.ansi {
+		w = smclcd.NewTerminal(l)
+	}
 
 	var b bytes.Buffer
+	var prev *smclcd.Frame
 	for {
 		c := exec.Command(cmd.name, cmd.args...)
 		c.Stdout = &b
@@ -169,13 +206,24 @@ func (cmd *watchCmd) Run() error {
 		if err = c.Run(); err != nil {
 			return fmt.Errorf("failed to execute command: %v", cmd)
 		}
-		if err = l.Clear(); err != nil {
-			return err
-		}
-		if _, err = b.WriteTo(l); err != nil {
-			if err != io.EOF {
+		if cmd.frames {
+			f, err := l.RenderFrame(b.String())
+			if err != nil {
+				return err
+			}
+			if err = l.DrawTransition(prev, &f, cmd.trans, cmd.step); err != nil {
 				return err
 			}
+			prev = &f
+		} else {
+			if err = l.Clear(); err != nil {
+				return err
+			}
+			if _, err = w.Write(smclcd.Cells(b.String())); err != nil {
+				if err != io.EOF {
+					return err
+				}
+			}
 		}
 		b.Reset()
 		time.Sleep(cmd.n)
@@ -28,12 +28,14 @@ import (
 	"io"
 	"strings"
 
+	"github.com/sstallion/go-smclcd"
 	"github.com/sstallion/go-tools/command"
 )
 
 type writeCmd struct {
 	flags *flag.FlagSet
 	clear bool
+	mode  smclcd.TextMode
 	x, y  uint
 	args  []string
 }
@@ -42,6 +44,7 @@ func init() {
 	cmd := &writeCmd{flags: flag.NewFlagSet("write", flag.ExitOnError)}
 	cmd.flags.Usage = cmd.Usage
 	cmd.flags.BoolVar(&cmd.clear, "clear", false, "TODO")
+	cmd.flags.Func("text", "`mode`", cmd.parseTextMode)
 	cmd.flags.UintVar(&cmd.x, "x", 0, "`col`")
 	cmd.flags.UintVar(&cmd.y, "y", 0, "`line`")
 	command.Add(cmd)
@@ -61,7 +64,7 @@ TODO.
 
 Usage:
 
-  {{ .Program }} [global flags] {{ .Name }} [-x col] [-y line] arguments...
+  {{ .Program }} [global flags] {{ .Name }} [-text mode] [-x col] [-y line] arguments...
 
 Flags:
 
@@ -83,6 +86,11 @@ func (cmd *writeCmd) Parse(arguments []string) error {
 	return nil
 }
 
+func (cmd *writeCmd) parseTextMode(s string) (err error) {
+	cmd.mode, err = parseTextMode(s)
+	return
+}
+
 func (cmd *writeCmd) Run() error {
 	l, err := openLCD()
 	if err != nil {
@@ -90,6 +98,7 @@ func (cmd *writeCmd) Run() error {
 	}
 	defer l.Close()
 
+	l.SetTextMode(cmd.mode)
 	if cmd.clear {
 		if err = l.Clear(); err != nil {
 			return err
@@ -101,7 +110,7 @@ func (cmd *writeCmd) Run() error {
 		return err
 	}
 
-	b := []byte(strings.Join(cmd.args, " "))
+	b := smclcd.Cells(strings.Join(cmd.args, " "))
 	if _, err = l.Write(b); err != nil {
 		if err != io.EOF {
 			return err
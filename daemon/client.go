@@ -0,0 +1,320 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// ErrClosed is returned by requests on a closed connection.
+var ErrClosed = errors.New("daemon: connection closed")
+
+// Client is a connection to a display served by Server. It implements
+// smclcd.Display along with SetLine, SetRegion, SetTextMode, and SetGlyph,
+// and is safe for concurrent use by multiple goroutines. Operations
+// requiring an *smclcd.LCD, such as DrawFrame, marquees, and the menu,
+// screen, and notify packages, are not available to clients.
+type Client struct {
+	c net.Conn
+
+	wmu sync.Mutex
+	enc *json.Encoder
+
+	mu     sync.Mutex
+	id     uint64
+	calls  map[uint64]chan *response
+	err    error
+	keys   chan smclcd.Key
+	subbed bool
+}
+
+// Dial connects to the daemon listening on the Unix domain socket at
+// path.
+func Dial(path string) (*Client, error) {
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient returns a Client communicating over c.
+func NewClient(c net.Conn) *Client {
+	cl := &Client{
+		c:     c,
+		enc:   json.NewEncoder(c),
+		calls: make(map[uint64]chan *response),
+		keys:  make(chan smclcd.Key, maxKeys),
+	}
+	go cl.recv()
+	return cl
+}
+
+func (cl *Client) recv() {
+	r := bufio.NewScanner(cl.c)
+	r.Buffer(nil, 1<<16)
+	var err error
+	for r.Scan() {
+		var resp response
+		if err = json.Unmarshal(r.Bytes(), &resp); err != nil {
+			break
+		}
+		if resp.Key != nil {
+			select {
+			case cl.keys <- *resp.Key:
+			default:
+			}
+			continue
+		}
+		cl.mu.Lock()
+		call := cl.calls[resp.ID]
+		delete(cl.calls, resp.ID)
+		cl.mu.Unlock()
+		if call != nil {
+			call <- &resp
+		}
+	}
+	if err == nil {
+		err = r.Err()
+	}
+	if err == nil {
+		err = ErrClosed
+	}
+
+	cl.mu.Lock()
+	cl.err = err
+	for id, call := range cl.calls {
+		close(call)
+		delete(cl.calls, id)
+	}
+	close(cl.keys)
+	cl.mu.Unlock()
+}
+
+func (cl *Client) call(req *request) (*response, error) {
+	call := make(chan *response, 1)
+
+	cl.mu.Lock()
+	if cl.err != nil {
+		cl.mu.Unlock()
+		return nil, cl.err
+	}
+	cl.id++
+	req.ID = cl.id
+	cl.calls[req.ID] = call
+	cl.mu.Unlock()
+
+	cl.wmu.Lock()
+	err := cl.enc.Encode(req)
+	cl.wmu.Unlock()
+	if err != nil {
+		cl.mu.Lock()
+		delete(cl.calls, req.ID)
+		cl.mu.Unlock()
+		return nil, err
+	}
+
+	resp, ok := <-call
+	if !ok {
+		cl.mu.Lock()
+		defer cl.mu.Unlock()
+		return nil, cl.err
+	}
+	switch resp.Error {
+	case "":
+		return resp, nil
+	case io.EOF.Error():
+		return resp, io.EOF
+	default:
+		return resp, errors.New(resp.Error)
+	}
+}
+
+func (cl *Client) Close() error {
+	return cl.c.Close()
+}
+
+func (cl *Client) Version() (string, error) {
+	resp, err := cl.call(&request{Method: "version"})
+	if err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
+
+func (cl *Client) Clear() error {
+	_, err := cl.call(&request{Method: "clear"})
+	return err
+}
+
+func (cl *Client) Home() error {
+	_, err := cl.call(&request{Method: "home"})
+	return err
+}
+
+func (cl *Client) SetCursor(state smclcd.Cursor) error {
+	_, err := cl.call(&request{Method: "cursor", State: byte(state)})
+	return err
+}
+
+func (cl *Client) AdvanceCursor(n int) error {
+	_, err := cl.call(&request{Method: "advance", N: n})
+	return err
+}
+
+func (cl *Client) MoveCursor(y, x int) error {
+	_, err := cl.call(&request{Method: "move", Y: y, X: x})
+	return err
+}
+
+func (cl *Client) Write(p []byte) (int, error) {
+	resp, err := cl.call(&request{Method: "write", Data: p})
+	if resp == nil {
+		return 0, err
+	}
+	return resp.N, err
+}
+
+func (cl *Client) WriteAt(p []byte, off int64) (int, error) {
+	resp, err := cl.call(&request{Method: "writeat", Data: p, Offset: off})
+	if resp == nil {
+		return 0, err
+	}
+	return resp.N, err
+}
+
+func (cl *Client) Read(p []byte) (int, error) {
+	resp, err := cl.call(&request{Method: "read", N: len(p)})
+	if resp == nil {
+		return 0, err
+	}
+	return copy(p, resp.Data), err
+}
+
+func (cl *Client) ReadAt(p []byte, off int64) (int, error) {
+	resp, err := cl.call(&request{Method: "readat", N: len(p), Offset: off})
+	if resp == nil {
+		return 0, err
+	}
+	n := copy(p, resp.Data)
+	if err == nil && n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (cl *Client) Seek(offset int64, whence int) (int64, error) {
+	resp, err := cl.call(&request{Method: "seek", Offset: offset, Whence: whence})
+	if resp == nil {
+		return 0, err
+	}
+	return resp.Offset, err
+}
+
+// GetInput returns the next key event. The first call subscribes the
+// connection to key events; events are dropped if not read promptly.
+func (cl *Client) GetInput() (key smclcd.Key, err error) {
+	cl.mu.Lock()
+	subbed := cl.subbed
+	cl.mu.Unlock()
+
+	if !subbed {
+		if _, err = cl.call(&request{Method: "keys"}); err != nil {
+			return
+		}
+		cl.mu.Lock()
+		cl.subbed = true
+		cl.mu.Unlock()
+	}
+	key, ok := <-cl.keys
+	if !ok {
+		cl.mu.Lock()
+		defer cl.mu.Unlock()
+		err = cl.err
+	}
+	return
+}
+
+func (cl *Client) SetBacklight(state smclcd.Backlight) error {
+	_, err := cl.call(&request{Method: "backlight", State: byte(state)})
+	return err
+}
+
+func (cl *Client) SetRegion(r smclcd.Region, text string) error {
+	_, err := cl.call(&request{Method: "region", Region: &r, Text: text})
+	return err
+}
+
+func (cl *Client) SetLine(n int, text string, a smclcd.Align) error {
+	return cl.SetRegion(smclcd.Region{Y: n, Width: smclcd.Columns, Height: 1, Align: a}, text)
+}
+
+// SetTextMode sets the text mode used by Write on this connection. Unlike
+// the method of smclcd.LCD, it returns an error as the mode is held by the
+// server.
+func (cl *Client) SetTextMode(mode smclcd.TextMode) error {
+	_, err := cl.call(&request{Method: "textmode", State: byte(mode)})
+	return err
+}
+
+// SetGlyph programs slot with g. Glyph slots are shared by all clients of
+// the server.
+func (cl *Client) SetGlyph(slot int, g smclcd.Glyph) error {
+	_, err := cl.call(&request{Method: "glyph", N: slot, Data: g[:]})
+	return err
+}
+
+func (cl *Client) Print(a ...interface{}) (int, error) {
+	return fmt.Fprint(cl, a...)
+}
+
+func (cl *Client) Printf(format string, a ...interface{}) (int, error) {
+	return fmt.Fprintf(cl, format, a...)
+}
+
+func (cl *Client) Println(a ...interface{}) (int, error) {
+	return fmt.Fprintln(cl, a...)
+}
+
+func (cl *Client) Scan(a ...interface{}) (int, error) {
+	return fmt.Fscan(cl, a...)
+}
+
+func (cl *Client) Scanf(format string, a ...interface{}) (int, error) {
+	return fmt.Fscanf(cl, format, a...)
+}
+
+func (cl *Client) Scanln(a ...interface{}) (int, error) {
+	return fmt.Fscanln(cl, a...)
+}
+
+var _ smclcd.Display = (*Client)(nil)
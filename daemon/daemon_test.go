@@ -0,0 +1,196 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package daemon
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+func serve(t *testing.T) (*fakehid.Device, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "smclcd.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := fakehid.New(smclcd.ErrTimeout)
+	go NewServer(smclcd.New(d)).Serve(ln)
+	t.Cleanup(func() {
+		ln.Close()
+		d.Close()
+	})
+	return d, path
+}
+
+func dial(t *testing.T, path string) *Client {
+	t.Helper()
+	cl, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cl.Close() })
+	return cl
+}
+
+func TestLoopback(t *testing.T) {
+	d, path := serve(t)
+	a, b := dial(t, path), dial(t, path)
+
+	if err := a.SetLine(0, "end", smclcd.AlignRight); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Seek(smclcd.Columns, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte("ab")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Write([]byte("cd")); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		cl   *Client
+		want int64
+	}{
+		{"a", a, smclcd.Columns + 2},
+		{"b", b, 2},
+	} {
+		if pos, err := tt.cl.Seek(0, io.SeekCurrent); err != nil || pos != tt.want {
+			t.Errorf("%s: Seek() = %d, %v, want %d", tt.name, pos, err, tt.want)
+		}
+	}
+
+	// Text modes are also held per connection.
+	if err := a.SetTextMode(smclcd.TextControl); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte("p\rq")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Write([]byte("\rz")); err != nil {
+		t.Fatal(err)
+	}
+	want := "abp?q        endzd              "
+	if got := d.Text(); got != want {
+		t.Errorf("display = %q, want %q", got, want)
+	}
+	p := make([]byte, 3)
+	if n, err := b.ReadAt(p, 13); err != nil || string(p[:n]) != "end" {
+		t.Errorf("ReadAt() = %q, %v, want %q", p[:n], err, "end")
+	}
+
+	g := smclcd.Glyph{0x1f, 0, 0x1f, 0, 0x1f, 0, 0x1f, 0}
+	if err := a.SetGlyph(3, g); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Glyph(3); got != g {
+		t.Errorf("glyph 3 = %v, want %v", got, g)
+	}
+	if err := b.SetGlyph(smclcd.GlyphSlots, g); err == nil {
+		t.Error("SetGlyph() with invalid slot succeeded")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	d, path := serve(t)
+	a, b := dial(t, path), dial(t, path)
+
+	keys := make(chan smclcd.Key, 1)
+	go func() {
+		key, err := a.GetInput()
+		if err != nil {
+			t.Error(err)
+		}
+		keys <- key
+	}()
+	for {
+		a.mu.Lock()
+		subbed := a.subbed
+		a.mu.Unlock()
+		if subbed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	want := smclcd.Key{Code: smclcd.KeyEnter, Event: smclcd.KeyPress}
+	d.Key(byte(want.Code), byte(want.Event))
+	select {
+	case key := <-keys:
+		if key != want {
+			t.Errorf("GetInput() = %v, want %v", key, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("key not received")
+	}
+
+	// b did not subscribe and receives no keys.
+	select {
+	case key := <-b.keys:
+		t.Errorf("unsubscribed client received %v", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	d := fakehid.New(smclcd.ErrTimeout)
+	defer d.Close()
+	s := NewServer(smclcd.New(d))
+	go s.pumpKeys()
+
+	// Writes to a pipe block until read, so keys back up at once.
+	c, sc := net.Pipe()
+	defer c.Close()
+	go s.serveConn(sc)
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.Write([]byte(`{"id":1,"method":"keys"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(c)
+	if _, err := r.ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < maxKeys+4; i++ {
+		d.Key(byte(smclcd.KeyUp), byte(i%2))
+		time.Sleep(5 * time.Millisecond)
+	}
+	for {
+		if _, err := r.ReadString('\n'); err != nil {
+			if err != io.EOF {
+				t.Errorf("read error = %v, want %v", err, io.EOF)
+			}
+			return
+		}
+	}
+}
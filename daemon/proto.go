@@ -0,0 +1,85 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package daemon shares a display between processes over a Unix domain
+// socket.
+//
+// The protocol is line-delimited JSON. Each request names a method and
+// carries an ID that is echoed in its response:
+//
+//	{"id":1,"method":"write","data":"SGVsbG8="}
+//	{"id":1,"n":5}
+//
+// Byte slices are encoded in base64. A non-empty "error" indicates the
+// request failed; "EOF" is reported when the end of the display is
+// reached. The methods are write, read, writeat, readat, seek, clear,
+// home, cursor, advance, move, backlight, version, region, textmode,
+// glyph, and keys. The region method sets text in a region of the
+// display:
+//
+//	{"id":2,"method":"region","region":{"Y":1,"Width":16,"Height":1,"Align":1},"text":"Hello"}
+//
+// The textmode method sets the text mode used by write in its state. The
+// glyph method programs the slot in n with the 8 rows in data; glyph
+// slots are shared by all connections. The keys
+// method subscribes the connection to key events, which are sent as
+// messages without an ID:
+//
+//	{"key":{"Code":4,"Event":1}}
+//
+// Each connection has its own cursor position and text mode, so clients
+// do not disturb one another. A connection that does not keep up with key
+// events is closed rather than miss a release and leave a key held.
+package daemon
+
+import (
+	"github.com/sstallion/go-smclcd"
+)
+
+// DefaultSocket is the default path of the daemon socket.
+const DefaultSocket = "/run/smclcd.sock"
+
+type request struct {
+	ID     uint64 `json:"id"`
+	Method string `json:"method"`
+	Data   []byte `json:"data,omitempty"`
+	N      int    `json:"n,omitempty"`
+	Y      int    `json:"y,omitempty"`
+	X      int    `json:"x,omitempty"`
+	Offset int64  `json:"offset,omitempty"`
+	Whence int    `json:"whence,omitempty"`
+	State  byte   `json:"state,omitempty"`
+
+	Region *smclcd.Region `json:"region,omitempty"`
+	Text   string         `json:"text,omitempty"`
+}
+
+type response struct {
+	ID      uint64      `json:"id,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Data    []byte      `json:"data,omitempty"`
+	N       int         `json:"n,omitempty"`
+	Offset  int64       `json:"offset,omitempty"`
+	Version string      `json:"version,omitempty"`
+	Key     *smclcd.Key `json:"key,omitempty"`
+}
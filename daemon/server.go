@@ -0,0 +1,239 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// maxKeys is the number of key events buffered for each connection; a
+// connection that falls further behind is closed.
+const maxKeys = 16
+
+// Server serves a display to clients. Requests from all connections are
+// serialized.
+type Server struct {
+	LCD *smclcd.LCD
+
+	mu    sync.Mutex
+	conns map[*conn]struct{}
+	once  sync.Once
+}
+
+type conn struct {
+	c    net.Conn
+	pos  int64
+	mode smclcd.TextMode
+	wmu  sync.Mutex
+	enc  *json.Encoder
+	keys chan smclcd.Key
+}
+
+func NewServer(l *smclcd.LCD) *Server {
+	return &Server{
+		LCD:   l,
+		conns: make(map[*conn]struct{}),
+	}
+}
+
+// Serve accepts connections on ln until an error occurs.
+func (s *Server) Serve(ln net.Listener) error {
+	s.once.Do(func() { go s.pumpKeys() })
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(c)
+	}
+}
+
+func (s *Server) pumpKeys() {
+	for {
+		key, err := s.LCD.GetInput()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		for c := range s.conns {
+			if c.keys == nil {
+				continue
+			}
+			select {
+			case c.keys <- key:
+			default:
+				// Dropping a single event could leave a key held;
+				// disconnect the slow subscriber instead.
+				c.c.Close()
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) serveConn(nc net.Conn) {
+	c := &conn{c: nc, enc: json.NewEncoder(nc)}
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		close(done)
+		nc.Close()
+	}()
+
+	r := bufio.NewScanner(nc)
+	r.Buffer(nil, 1<<16)
+	for r.Scan() {
+		var req request
+		var resp response
+		if err := json.Unmarshal(r.Bytes(), &req); err != nil {
+			resp.Error = "invalid request: " + err.Error()
+		} else {
+			resp = s.handle(c, &req)
+			if req.Method == "keys" && resp.Error == "" {
+				s.subscribe(c, done)
+			}
+		}
+		if c.send(&resp) != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) subscribe(c *conn, done chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.keys != nil {
+		return
+	}
+	c.keys = make(chan smclcd.Key, maxKeys)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case key := <-c.keys:
+				if c.send(&response{Key: &key}) != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (c *conn) send(resp *response) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.enc.Encode(resp)
+}
+
+func (s *Server) handle(c *conn, req *request) (resp response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.LCD
+	resp.ID = req.ID
+	l.SetTextMode(c.mode)
+	_, err := l.Seek(c.pos, io.SeekStart)
+	if err == nil {
+		switch req.Method {
+		case "write":
+			resp.N, err = l.Write(req.Data)
+		case "read":
+			resp.Data = makeBuf(req.N)
+			resp.N, err = l.Read(resp.Data)
+			resp.Data = resp.Data[:resp.N]
+		case "writeat":
+			resp.N, err = l.WriteAt(req.Data, req.Offset)
+		case "readat":
+			resp.Data = makeBuf(req.N)
+			resp.N, err = l.ReadAt(resp.Data, req.Offset)
+			resp.Data = resp.Data[:resp.N]
+		case "seek":
+			resp.Offset, err = l.Seek(req.Offset, req.Whence)
+		case "clear":
+			err = l.Clear()
+		case "home":
+			err = l.Home()
+		case "cursor":
+			err = l.SetCursor(smclcd.Cursor(req.State))
+		case "advance":
+			err = l.AdvanceCursor(req.N)
+		case "move":
+			err = l.MoveCursor(req.Y, req.X)
+		case "backlight":
+			err = l.SetBacklight(smclcd.Backlight(req.State))
+		case "version":
+			resp.Version, err = l.Version()
+		case "region":
+			if req.Region == nil {
+				err = errors.New("missing region")
+				break
+			}
+			err = l.SetRegion(*req.Region, req.Text)
+		case "textmode":
+			c.mode = smclcd.TextMode(req.State)
+		case "glyph":
+			var g smclcd.Glyph
+			if len(req.Data) != len(g) {
+				err = errors.New("invalid glyph")
+				break
+			}
+			copy(g[:], req.Data)
+			err = l.SetGlyph(req.N, g)
+		case "keys":
+		default:
+			err = errors.New("unknown method: " + req.Method)
+		}
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if pos, err := l.Seek(0, io.SeekCurrent); err == nil {
+		c.pos = pos
+	}
+	return
+}
+
+// makeBuf returns a buffer of n bytes, limited to the size of the display.
+func makeBuf(n int) []byte {
+	if n < 0 {
+		n = 0
+	} else if n > smclcd.Lines*smclcd.Columns {
+		n = smclcd.Lines * smclcd.Columns
+	}
+	return make([]byte, n)
+}
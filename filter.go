@@ -0,0 +1,171 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"sync"
+	"time"
+)
+
+// FilterStats counts the key events discarded or synthesized by a
+// KeyFilter.
+type FilterStats struct {
+	Debounced   uint64 // Repeated presses and releases within Debounce
+	Held        uint64 // Presses of keys already held
+	Orphaned    uint64 // Releases without a matching press
+	Synthesized uint64 // Releases synthesized after MaxHold
+}
+
+// KeyFilter removes spurious events from the key events returned by a
+// KeyReader. Identical events for a key within Debounce of each other
+// and presses of keys already held are discarded, as are releases of
+// keys that are not held. If a key is held longer than MaxHold, a
+// release is synthesized. A zero duration disables the corresponding
+// filter.
+type KeyFilter struct {
+	Debounce time.Duration
+	MaxHold  time.Duration
+
+	r    KeyReader
+	keys <-chan keyResult
+	err  error
+	done chan struct{}
+	once sync.Once
+	down KeySet
+	last [KeyCancel + 1]Key
+	at   [KeyCancel + 1]time.Time
+
+	mu    sync.Mutex
+	stats FilterStats
+}
+
+func NewKeyFilter(r KeyReader) *KeyFilter {
+	return &KeyFilter{
+		Debounce: 50 * time.Millisecond,
+		MaxHold:  time.Minute,
+		r:        r,
+		done:     make(chan struct{}),
+	}
+}
+
+// Close stops reading key events from the underlying KeyReader. Pending
+// and subsequent calls to GetInput return ErrClosed.
+func (f *KeyFilter) Close() {
+	f.once.Do(func() { close(f.done) })
+}
+
+// Stats returns the number of events filtered so far. It is safe to call
+// Stats concurrently with GetInput.
+func (f *KeyFilter) Stats() FilterStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+func (f *KeyFilter) count(n *uint64) {
+	f.mu.Lock()
+	*n++
+	f.mu.Unlock()
+}
+
+func (f *KeyFilter) GetInput() (key Key, err error) {
+	if f.keys == nil {
+		f.keys = readKeys(f.r, f.done)
+	}
+	for {
+		if f.err != nil {
+			return key, f.err
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if code, t, ok := f.expiry(); ok {
+			timer = time.NewTimer(time.Until(t))
+			timeout = timer.C
+			key = Key{code, KeyRelease}
+		}
+		select {
+		case <-f.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			key, f.err = Key{}, ErrClosed
+		case r := <-f.keys:
+			if timer != nil {
+				timer.Stop()
+			}
+			if r.err != nil {
+				f.err = r.err
+				continue
+			}
+			if f.accept(r.key, time.Now()) {
+				return r.key, nil
+			}
+		case <-timeout:
+			f.down &^= Keys(key.Code)
+			f.last[key.Code] = key
+			f.count(&f.stats.Synthesized)
+			return
+		}
+	}
+}
+
+func (f *KeyFilter) expiry() (code KeyCode, t time.Time, ok bool) {
+	if f.MaxHold <= 0 {
+		return
+	}
+	for c := KeyUp; c <= KeyCancel; c++ {
+		if f.down.Has(c) && (!ok || f.at[c].Before(t)) {
+			code, t, ok = c, f.at[c], true
+		}
+	}
+	t = t.Add(f.MaxHold)
+	return
+}
+
+func (f *KeyFilter) accept(key Key, now time.Time) bool {
+	if key.Code > KeyCancel {
+		return true // unknown key
+	}
+	if key == f.last[key.Code] && now.Sub(f.at[key.Code]) < f.Debounce {
+		f.count(&f.stats.Debounced)
+		return false
+	}
+	switch key.Event {
+	case KeyPress:
+		if f.down.Has(key.Code) {
+			f.count(&f.stats.Held)
+			return false
+		}
+		f.down |= Keys(key.Code)
+	case KeyRelease:
+		if !f.down.Has(key.Code) {
+			f.count(&f.stats.Orphaned)
+			return false
+		}
+		f.down &^= Keys(key.Code)
+	}
+	f.last[key.Code], f.at[key.Code] = key, now
+	return true
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+func TestKeyFilterDebounce(t *testing.T) {
+	const ms = time.Millisecond
+	tests := []struct {
+		at   time.Duration
+		key  Key
+		want bool
+	}{
+		{0, Key{KeyUp, KeyPress}, true},
+		{10 * ms, Key{KeyUp, KeyPress}, false},    // debounced
+		{20 * ms, Key{KeyUp, KeyRelease}, true},   // different event
+		{30 * ms, Key{KeyUp, KeyRelease}, false},  // debounced
+		{100 * ms, Key{KeyUp, KeyRelease}, false}, // orphaned
+		{110 * ms, Key{KeyDown, KeyRelease}, false},
+		{120 * ms, Key{KeyDown, KeyPress}, true},
+		{200 * ms, Key{KeyDown, KeyPress}, false}, // already held
+		{210 * ms, Key{KeyCode(0x7f), KeyPress}, true},
+	}
+	f := NewKeyFilter(nil)
+	start := time.Now()
+	for _, tt := range tests {
+		if got := f.accept(tt.key, start.Add(tt.at)); got != tt.want {
+			t.Errorf("at %v: accept(%v) = %v, want %v", tt.at, tt.key, got, tt.want)
+		}
+	}
+	want := FilterStats{Debounced: 2, Held: 1, Orphaned: 2}
+	if stats := f.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestKeyFilterMaxHold(t *testing.T) {
+	const ms = time.Millisecond
+	l := New(newFakeDevice(
+		fakehid.Report{At: 0, Report: upPress},
+		fakehid.Report{At: 200 * ms, Report: upRelease},
+	))
+	f := NewKeyFilter(l)
+	f.MaxHold = 100 * ms
+	defer f.Close()
+
+	start := time.Now()
+	for _, want := range []Key{{KeyUp, KeyPress}, {KeyUp, KeyRelease}} {
+		key, err := f.GetInput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != want {
+			t.Errorf("GetInput() = %v, want %v", key, want)
+		}
+	}
+	if d := time.Since(start); d < 100*ms || d >= 200*ms {
+		t.Errorf("release synthesized after %v, want %v", d, 100*ms)
+	}
+
+	// The physical release is discarded as it no longer matches a press.
+	time.AfterFunc(300*ms, f.Close)
+	if key, err := f.GetInput(); err != ErrClosed {
+		t.Errorf("GetInput() = %v, %v, want %v", key, err, ErrClosed)
+	}
+	want := FilterStats{Orphaned: 1, Synthesized: 1}
+	if stats := f.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"strings"
+)
+
+// GlyphSet maps runes to custom glyphs.
+type GlyphSet map[rune]Glyph
+
+// DefaultFont holds glyphs for runes missing from the character ROM,
+// including accented Latin letters, Greek and Cyrillic letters that
+// differ from their Latin counterparts, and common symbols.
+var DefaultFont = GlyphSet{}
+
+// romChars maps runes to characters in the character ROM outside of
+// printable ASCII, including look-alike Greek and Cyrillic letters.
+var romChars = map[rune]byte{
+	'→': 0x7e, '←': 0x7f, '°': 0xdf, 'α': 0xe0, 'ä': 0xe1, 'β': 0xe2,
+	'ε': 0xe3, 'μ': 0xe4, 'µ': 0xe4, 'σ': 0xe5, 'ρ': 0xe6, '√': 0xe8,
+	'¢': 0xec, 'ñ': 0xee, 'ö': 0xef, 'θ': 0xf2, '∞': 0xf3, 'Ω': 0xf4,
+	'ü': 0xf5, 'Σ': 0xf6, 'π': 0xf7, '÷': 0xfd, '█': 0xff, '¥': 0x5c,
+
+	// Greek
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K',
+	'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+	'ο': 'o', 'κ': 'k', 'ι': 'i', 'υ': 'u', 'ν': 'v',
+
+	// Cyrillic
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O',
+	'Р': 'P', 'С': 'C', 'Т': 'T', 'Х': 'X', 'а': 'a', 'е': 'e', 'о': 'o',
+	'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x', 'і': 'i', 'І': 'I',
+
+	// Punctuation
+	'‘': '\'', '’': '\'', '“': '"', '”': '"', '–': '-', '—': '-',
+	' ': ' ',
+}
+
+// romRunes maps characters in the character ROM outside of printable
+// ASCII to runes. The ROM has a yen sign in place of the backslash.
+var romRunes = map[byte]rune{
+	0x5c: '¥', 0x7e: '→', 0x7f: '←', 0xdf: '°', 0xe0: 'α', 0xe1: 'ä',
+	0xe2: 'β', 0xe3: 'ε', 0xe4: 'µ', 0xe5: 'σ', 0xe6: 'ρ', 0xe8: '√',
+	0xec: '¢', 0xee: 'ñ', 0xef: 'ö', 0xf2: 'θ', 0xf3: '∞', 0xf4: 'Ω',
+	0xf5: 'ü', 0xf6: 'Σ', 0xf7: 'π', 0xfd: '÷', 0xff: '█',
+}
+
+// translit maps runes to approximations used when no glyph slot is
+// available.
+var translit = map[rune]byte{}
+
+func init() {
+	pairs := []string{
+		"àáâãäåāą", "a", "ÀÁÂÃÄÅĀĄ", "A", "çćč", "c", "ÇĆČ", "C",
+		"ďđ", "d", "ĎĐ", "D", "èéêëēęě", "e", "ÈÉÊËĒĘĚ", "E",
+		"ìíîïī", "i", "ÌÍÎÏĪ", "I", "łľ", "l", "ŁĽ", "L", "ñńň", "n",
+		"ÑŃŇ", "N", "òóôõöøō", "o", "ÒÓÔÕÖØŌ", "O", "řŕ", "r", "ŘŔ", "R",
+		"śšşß", "s", "ŚŠŞ", "S", "ťţ", "t", "ŤŢ", "T", "ùúûüūůű", "u",
+		"ÙÚÛÜŪŮŰ", "U", "ýÿ", "y", "Ý", "Y", "źżž", "z", "ŹŻŽ", "Z",
+		"æ", "a", "Æ", "A",
+
+		"γ", "g", "Γ", "G", "δ", "d", "Δ", "D", "ζ", "z", "η", "n",
+		"Θ", "O", "λ", "l", "Λ", "L", "ξ", "x", "Ξ", "X", "Π", "P",
+		"ς", "s", "τ", "t", "φ", "f", "Φ", "F", "χ", "x", "ψ", "y",
+		"Ψ", "Y", "ω", "w",
+
+		"б", "b", "Б", "B", "в", "v", "г", "g", "Г", "G", "д", "d",
+		"Д", "D", "ёэ", "e", "ЁЭ", "E", "жз", "z", "ЖЗ", "Z", "ий", "i",
+		"ИЙ", "I", "к", "k", "л", "l", "Л", "L", "м", "m", "н", "n",
+		"п", "p", "П", "P", "т", "t", "У", "U", "ф", "f", "Ф", "F",
+		"цч", "c", "ЦЧ", "C", "шщ", "s", "ШЩ", "S", "ъь", "'", "ЪЬ", "'",
+		"ы", "y", "Ы", "Y", "ю", "u", "Ю", "U", "я", "a", "Я", "A",
+
+		"✓✔", "v", "✗✘", "x", "♥", "*", "•", "*", "↑", "^", "↓", "v",
+		"€", "E", "£", "L", "±", "+", "…", ".", "♪", "#", "\\", "/",
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		for _, r := range pairs[i] {
+			translit[r] = pairs[i+1][0]
+		}
+	}
+
+	for r, rows := range fontRows {
+		DefaultFont[r] = mustGlyph(rows)
+	}
+}
+
+func mustGlyph(rows string) (g Glyph) {
+	for y, row := range strings.Fields(rows) {
+		for x, c := range row {
+			if c == '#' {
+				g[y] |= 0x10 >> x
+			}
+		}
+	}
+	return
+}
+
+var fontRows = map[rune]string{
+	'à': ".#... ..#.. .###. ....# .#### #...# .####",
+	'á': "...#. ..#.. .###. ....# .#### #...# .####",
+	'â': "..#.. .#.#. .###. ....# .#### #...# .####",
+	'å': "..#.. .#.#. ..#.. .###. ....# .#### #...# .####",
+	'æ': "..... ..... ##.#. ..#.# .#### #.#.. .####",
+	'ç': "..... .###. #.... #.... #...# .###. ..#.. .##..",
+	'è': ".#... ..#.. .###. #...# ##### #.... .###.",
+	'é': "...#. ..#.. .###. #...# ##### #.... .###.",
+	'ê': "..#.. .#.#. .###. #...# ##### #.... .###.",
+	'ë': ".#.#. ..... .###. #...# ##### #.... .###.",
+	'ì': ".#... ..#.. ..... .##.. ..#.. ..#.. .###.",
+	'í': "...#. ..#.. ..... .##.. ..#.. ..#.. .###.",
+	'î': "..#.. .#.#. ..... .##.. ..#.. ..#.. .###.",
+	'ï': ".#.#. ..... .##.. ..#.. ..#.. ..#.. .###.",
+	'ò': ".#... ..#.. .###. #...# #...# #...# .###.",
+	'ó': "...#. ..#.. .###. #...# #...# #...# .###.",
+	'ô': "..#.. .#.#. .###. #...# #...# #...# .###.",
+	'ø': "..... ..... .###. #..## #.#.# ##..# .###.",
+	'ù': ".#... ..#.. #...# #...# #...# #..## .##.#",
+	'ú': "...#. ..#.. #...# #...# #...# #..## .##.#",
+	'û': "..#.. .#.#. #...# #...# #...# #..## .##.#",
+	'ß': ".##.. #..#. #..#. #.#.. #..#. #...# #.##. #....",
+	'Ä': ".#.#. ..... .###. #...# ##### #...# #...#",
+	'Å': "..#.. .#.#. ..#.. .###. #...# ##### #...#",
+	'É': "...#. ..#.. ##### #.... ####. #.... #####",
+	'Ñ': ".##.# #..#. #...# ##..# #.#.# #..## #...#",
+	'Ö': ".#.#. ..... .###. #...# #...# #...# .###.",
+	'Ø': ".###. #..## #.#.# #.#.# #.#.# ##..# .###.",
+	'Ü': ".#.#. ..... #...# #...# #...# #...# .###.",
+
+	'Γ': "##### #.... #.... #.... #.... #.... #....",
+	'Δ': "..#.. ..#.. .#.#. .#.#. #...# #...# #####",
+	'Θ': ".###. #...# #...# ##### #...# #...# .###.",
+	'Λ': "..#.. ..#.. .#.#. .#.#. #...# #...# #...#",
+	'Ξ': "##### ..... ..... .###. ..... ..... #####",
+	'Π': "##### #...# #...# #...# #...# #...# #...#",
+	'Φ': "..#.. .###. #.#.# #.#.# #.#.# .###. ..#..",
+	'Ψ': "#.#.# #.#.# #.#.# .###. ..#.. ..#.. ..#..",
+	'γ': "..... #...# .#.#. .#.#. ..#.. ..#.. ..#..",
+	'δ': ".##.. #.... .#... .##.. #..#. #..#. .##..",
+	'λ': ".#... ..#.. ..#.. .#.#. .#.#. #...# #...#",
+	'τ': "..... ..... ##### ..#.. ..#.. ..#.. ...##",
+	'φ': "..... ..#.. .###. #.#.# #.#.# .###. ..#..",
+	'ψ': "..... #.#.# #.#.# #.#.# .###. ..#.. ..#..",
+	'ω': "..... ..... #...# #...# #.#.# #.#.# .#.#.",
+
+	'Б': "##### #.... #.... ####. #...# #...# ####.",
+	'Г': "##### #.... #.... #.... #.... #.... #....",
+	'Д': ".###. .#.#. .#.#. .#.#. .#.#. ##### #...#",
+	'Ж': "#.#.# #.#.# .###. ..#.. .###. #.#.# #.#.#",
+	'З': ".###. #...# ....# ..##. ....# #...# .###.",
+	'И': "#...# #...# #..## #.#.# ##..# #...# #...#",
+	'Й': ".#.#. ..#.. #...# #..## #.#.# ##..# #...#",
+	'Л': "..### .#..# .#..# .#..# .#..# .#..# #...#",
+	'П': "##### #...# #...# #...# #...# #...# #...#",
+	'У': "#...# #...# #...# .#### ....# #...# .###.",
+	'Ф': "..#.. .###. #.#.# #.#.# #.#.# .###. ..#..",
+	'Ц': "#..#. #..#. #..#. #..#. #..#. ##### ....#",
+	'Ч': "#...# #...# #...# .#### ....# ....# ....#",
+	'Ш': "#.#.# #.#.# #.#.# #.#.# #.#.# #.#.# #####",
+	'Щ': "#.#.# #.#.# #.#.# #.#.# #.#.# ##### ....#",
+	'Ъ': "##... .#... .#... .###. .#..# .#..# .###.",
+	'Ы': "#...# #...# #...# ##..# #.#.# #.#.# ##..#",
+	'Ь': "#.... #.... #.... ####. #...# #...# ####.",
+	'Э': ".###. #...# ....# ..### ....# #...# .###.",
+	'Ю': "#..#. #.#.# #.#.# ###.# #.#.# #.#.# #..#.",
+	'Я': ".#### #...# #...# .#### ..#.# .#..# #...#",
+
+	'✓': "..... ....# ...## #.##. ###.. .#... .....",
+	'✗': "..... #...# .#.#. ..#.. .#.#. #...# .....",
+	'♥': "..... .#.#. ##### ##### .###. ..#.. .....",
+	'↑': "..#.. .###. #.#.# ..#.. ..#.. ..#.. ..#..",
+	'↓': "..#.. ..#.. ..#.. ..#.. #.#.# .###. ..#..",
+	'€': "..### .#... ####. .#... ####. .#... ..###",
+	'£': "..##. .#..# .#... ###.. .#... .#..# #.##.",
+	'±': "..#.. ..#.. ##### ..#.. ..#.. ..... #####",
+	'…': "..... ..... ..... ..... ..... ..... #.#.#",
+	'♪': "..#.. ..##. ..#.# ..#.. ###.. ###.. .....",
+
+	// The character ROM has a yen sign in place of the backslash.
+	'\\': "..... #.... .#... ..#.. ...#. ....# .....",
+}
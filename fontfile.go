@@ -0,0 +1,237 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReadGlyphs parses glyphs in text format. Each glyph begins with a line
+// naming its rune, either as a single character or in U+XXXX notation,
+// followed by up to eight rows of five '.' (off) or '#' (on) pixels:
+//
+//	char ✓
+//	.....
+//	....#
+//	...##
+//	#.##.
+//	###..
+//	.#...
+//
+// Missing rows at the bottom are left blank. Blank lines and lines
+// beginning with ';' are ignored.
+func ReadGlyphs(r io.Reader) (GlyphSet, error) {
+	var set = make(GlyphSet)
+	var cur rune = -1
+	var g Glyph
+	var rows int
+	flush := func() {
+		if cur >= 0 {
+			set[cur] = g
+		}
+	}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "" || line[0] == ';':
+			continue
+		case strings.HasPrefix(line, "char"):
+			flush()
+			fields := strings.Fields(line)
+			if len(fields) != 2 || fields[0] != "char" {
+				return nil, fmt.Errorf("glyph: line %d: invalid char", n)
+			}
+			var err error
+			if cur, err = parseRune(fields[1]); err != nil {
+				return nil, fmt.Errorf("glyph: line %d: %v", n, err)
+			}
+			if _, ok := set[cur]; ok {
+				return nil, fmt.Errorf("glyph: line %d: duplicate char %q", n, cur)
+			}
+			g, rows = Glyph{}, 0
+		default:
+			if cur < 0 {
+				return nil, fmt.Errorf("glyph: line %d: row outside of char", n)
+			}
+			if len(line) != 5 {
+				return nil, fmt.Errorf("glyph: line %d: row must be 5 pixels wide", n)
+			}
+			if rows == len(g) {
+				return nil, fmt.Errorf("glyph: line %d: too many rows", n)
+			}
+			for x, c := range line {
+				switch c {
+				case '#':
+					g[rows] |= 0x10 >> x
+				case '.':
+				default:
+					return nil, fmt.Errorf("glyph: line %d: invalid pixel %q", n, c)
+				}
+			}
+			rows++
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return set, nil
+}
+
+func parseRune(s string) (rune, error) {
+	if strings.HasPrefix(s, "U+") || strings.HasPrefix(s, "u+") {
+		v, err := strconv.ParseUint(s[2:], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(v)) {
+			return 0, errors.New("invalid code point " + s)
+		}
+		return rune(v), nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || size != len(s) {
+		return 0, errors.New("invalid char " + s)
+	}
+	return r, nil
+}
+
+// ReadBDF parses glyphs from a font in Glyph Bitmap Distribution Format.
+// Encodings are interpreted as Unicode code points, and the font bounding
+// box must fit within a 5x8 cell.
+func ReadBDF(r io.Reader) (GlyphSet, error) {
+	var set = make(GlyphSet)
+	var fw, fh, fx, fy int
+	var box bool
+
+	var enc = -1
+	var w, h, xoff, yoff int
+	var g Glyph
+	var bitmap bool
+	var y int
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if bitmap && fields[0] != "ENDCHAR" {
+			if y >= h {
+				return nil, fmt.Errorf("bdf: line %d: too many bitmap rows", n)
+			}
+			v, err := strconv.ParseUint(fields[0], 16, 32)
+			if err != nil {
+				return nil, fmt.Errorf("bdf: line %d: invalid bitmap row", n)
+			}
+			bits := len(fields[0]) * 4
+			if bits < w {
+				return nil, fmt.Errorf("bdf: line %d: bitmap row too short", n)
+			}
+			for i := 0; i < w; i++ {
+				if v&(1<<(bits-1-i)) != 0 {
+					g[y+(fh+fy)-(h+yoff)] |= 0x10 >> (xoff - fx + i)
+				}
+			}
+			y++
+			continue
+		}
+
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			if err := scanInts(fields[1:], &fw, &fh, &fx, &fy); err != nil {
+				return nil, fmt.Errorf("bdf: line %d: %v", n, err)
+			}
+			if fw > 5 || fh > 8 {
+				return nil, fmt.Errorf("bdf: font bounding box %dx%d exceeds 5x8", fw, fh)
+			}
+			box = true
+		case "STARTCHAR":
+			enc, w, h, xoff, yoff = -1, 0, 0, 0, 0
+			g = Glyph{}
+		case "ENCODING":
+			if err := scanInts(fields[1:], &enc); err != nil {
+				return nil, fmt.Errorf("bdf: line %d: %v", n, err)
+			}
+		case "BBX":
+			if err := scanInts(fields[1:], &w, &h, &xoff, &yoff); err != nil {
+				return nil, fmt.Errorf("bdf: line %d: %v", n, err)
+			}
+			if !box {
+				return nil, fmt.Errorf("bdf: line %d: missing FONTBOUNDINGBOX", n)
+			}
+			if x := xoff - fx; x < 0 || x+w > 5 || yoff < fy || yoff+h > fy+fh {
+				return nil, fmt.Errorf("bdf: line %d: glyph exceeds font bounding box", n)
+			}
+		case "BITMAP":
+			bitmap, y = true, 0
+		case "ENDCHAR":
+			if enc >= 0 && utf8.ValidRune(rune(enc)) {
+				set[rune(enc)] = g
+			}
+			bitmap = false
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if !box {
+		return nil, errors.New("bdf: missing FONTBOUNDINGBOX")
+	}
+	return set, nil
+}
+
+func scanInts(fields []string, v ...*int) (err error) {
+	if len(fields) < len(v) {
+		return errors.New("missing values")
+	}
+	for i := range v {
+		if *v[i], err = strconv.Atoi(fields[i]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// LoadGlyphs reads glyphs from the named file. Files ending in .bdf are
+// parsed with ReadBDF, otherwise ReadGlyphs is used.
+func LoadGlyphs(name string) (GlyphSet, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(name), ".bdf") {
+		return ReadBDF(f)
+	}
+	return ReadGlyphs(f)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"strings"
+	"testing"
+)
+
+// check is the glyph used in the examples below.
+var check = Glyph{0x00, 0x01, 0x03, 0x16, 0x1c, 0x08, 0x00, 0x00}
+
+func TestReadGlyphs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want GlyphSet
+	}{
+		{"char ✓\n.....\n....#\n...##\n#.##.\n###..\n.#...\n", GlyphSet{'✓': check}},
+		{"; comment\n\nchar U+2713\n.....\n....#\n...##\n#.##.\n###..\n.#...\n.....\n.....\n",
+			GlyphSet{'✓': check}},
+		{"char a\n#####\nchar b\n", GlyphSet{'a': {0x1f}, 'b': {}}},
+	}
+	for _, tt := range tests {
+		set, err := ReadGlyphs(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("ReadGlyphs(%q): %v", tt.in, err)
+			continue
+		}
+		if len(set) != len(tt.want) {
+			t.Errorf("ReadGlyphs(%q) = %v, want %v", tt.in, set, tt.want)
+		}
+		for r, g := range tt.want {
+			if set[r] != g {
+				t.Errorf("ReadGlyphs(%q)[%q] = %v, want %v", tt.in, r, set[r], g)
+			}
+		}
+	}
+}
+
+func TestReadGlyphsErrors(t *testing.T) {
+	tests := []string{
+		".....\n",
+		"char\n",
+		"char ab\n",
+		"char U+D800\n",
+		"char a\n....\n",
+		"char a\n..x..\n",
+		"char a\n" + strings.Repeat(".....\n", 9),
+		"char a\nchar a\n",
+	}
+	for _, in := range tests {
+		if _, err := ReadGlyphs(strings.NewReader(in)); err == nil {
+			t.Errorf("ReadGlyphs(%q) succeeded", in)
+		}
+	}
+}
+
+const bdfHeader = "STARTFONT 2.1\nFONTBOUNDINGBOX 5 8 0 -1\nCHARS 1\n"
+
+func TestReadBDF(t *testing.T) {
+	tests := []struct {
+		in   string
+		want GlyphSet
+	}{
+		{
+			bdfHeader + "STARTCHAR check\nENCODING 10003\nBBX 5 6 0 1\nBITMAP\n00\n08\n18\nB0\nE0\n40\nENDCHAR\nENDFONT\n",
+			GlyphSet{'✓': check},
+		},
+		{
+			bdfHeader + "STARTCHAR bar\nENCODING 124\nBBX 1 8 2 -1\nBITMAP\n80\n80\n80\n80\n80\n80\n80\n80\nENDCHAR\nENDFONT\n",
+			GlyphSet{'|': {0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}},
+		},
+		{
+			bdfHeader + "STARTCHAR unencoded\nENCODING -1\nBBX 5 1 0 0\nBITMAP\nF8\nENDCHAR\nENDFONT\n",
+			GlyphSet{},
+		},
+	}
+	for _, tt := range tests {
+		set, err := ReadBDF(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("ReadBDF: %v\n%s", err, tt.in)
+			continue
+		}
+		if len(set) != len(tt.want) {
+			t.Errorf("ReadBDF = %v, want %v\n%s", set, tt.want, tt.in)
+		}
+		for r, g := range tt.want {
+			if set[r] != g {
+				t.Errorf("ReadBDF[%q] = %v, want %v", r, set[r], g)
+			}
+		}
+	}
+}
+
+func TestReadBDFErrors(t *testing.T) {
+	tests := []string{
+		"STARTFONT 2.1\nENDFONT\n",
+		"STARTFONT 2.1\nFONTBOUNDINGBOX 6 8 0 0\n",
+		"STARTFONT 2.1\nSTARTCHAR a\nBBX 5 8 0 0\n",
+		bdfHeader + "STARTCHAR a\nENCODING 97\nBBX 5 8 1 -1\n",
+		bdfHeader + "STARTCHAR a\nENCODING 97\nBBX 5 8 0 0\n",
+		bdfHeader + "STARTCHAR a\nENCODING\n",
+		bdfHeader + "STARTCHAR a\nENCODING 97\nBBX 5 1 0 0\nBITMAP\nZZ\nENDCHAR\n",
+		bdfHeader + "STARTCHAR a\nENCODING 97\nBBX 5 1 0 0\nBITMAP\nF8\nF8\nENDCHAR\n",
+		bdfHeader + "STARTCHAR a\nENCODING 97\nBBX 5 1 0 0\nBITMAP\nF\nENDCHAR\n",
+	}
+	for _, in := range tests {
+		if _, err := ReadBDF(strings.NewReader(in)); err == nil {
+			t.Errorf("ReadBDF succeeded\n%s", in)
+		}
+	}
+}
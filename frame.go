@@ -0,0 +1,149 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Frame holds the contents of the display, one byte per cell.
+type Frame [Lines * Columns]byte
+
+// NewFrame lays out text on a Frame. Lines are separated by newlines,
+// clipped to Columns, and padded with spaces.
+func NewFrame(text string) (f Frame) {
+	lines := strings.Split(text, "\n")
+	for y := 0; y < Lines; y++ {
+		var s string
+		if y < len(lines) {
+			s = lines[y]
+		}
+		copy(f[y*Columns:(y+1)*Columns], printable(align(s, Columns, AlignLeft)))
+	}
+	return
+}
+
+func (f *Frame) Line(n int) []byte {
+	return f[n*Columns : (n+1)*Columns]
+}
+
+func (f Frame) String() string {
+	lines := make([]string, Lines)
+	for y := range lines {
+		lines[y] = string(f.Line(y))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Text returns the contents of f as lines separated by newlines, with
+// characters from the character ROM converted to runes. Custom glyphs
+// and characters without an equivalent rune are replaced by '?'.
+func (f *Frame) Text() string {
+	var b strings.Builder
+	for i, c := range f {
+		if i > 0 && i%Columns == 0 {
+			b.WriteByte('\n')
+		}
+		if r, ok := romRunes[c]; ok {
+			b.WriteRune(r)
+		} else if c >= ' ' && c < 0x7e {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// ReadFrame reads the contents of the display. The cursor position is
+// preserved.
+func (l *LCD) ReadFrame() (f Frame, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err = l.readAt(f[:], 0)
+	return
+}
+
+// DrawFrame writes the cells of f that differ from prev, which holds the
+// current contents of the display. If prev is nil, all cells are
+// written. The cursor position is preserved.
+func (l *LCD) DrawFrame(prev, f *Frame) (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if prev == nil {
+		_, err = l.writeAt(f[:], 0)
+		return
+	}
+	for y := 0; y < Lines; y++ {
+		a, b := prev.Line(y), f.Line(y)
+		if bytes.Equal(a, b) {
+			continue
+		}
+		var start, end = 0, Columns
+		for a[start] == b[start] {
+			start++
+		}
+		for a[end-1] == b[end-1] {
+			end--
+		}
+		if _, err = l.writeAt(b[start:end], y*Columns+start); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// State is a snapshot of the display. Cursor and Backlight hold the
+// states last set; the backlight is assumed to be on when the display is
+// opened. Glyphs holds the contents of the glyph slots that have been
+// programmed.
+type State struct {
+	Frame     Frame
+	Glyphs    [GlyphSlots]Glyph
+	Cursor    Cursor
+	Line      int
+	Column    int
+	Backlight Backlight
+}
+
+// ReadState reads the contents of the display along with its cursor,
+// backlight, and glyph state.
+func (l *LCD) ReadState() (s State, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err = l.readAt(s.Frame[:], 0); err != nil {
+		return
+	}
+	for i, slot := range l.glyphs {
+		s.Glyphs[i] = slot.g
+	}
+	s.Cursor = l.cursor
+	s.Line, s.Column = int(l.pos)/Columns, int(l.pos)%Columns
+	s.Backlight = l.backlight
+	return
+}
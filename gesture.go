@@ -0,0 +1,291 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"sync"
+	"time"
+)
+
+type Gesture byte
+
+//go:generate stringer -type Gesture -trimprefix=Gesture
+
+const (
+	GestureClick Gesture = iota
+	GestureDoubleClick
+	GestureLongPress
+	GestureRepeat
+	GestureChord
+)
+
+// GestureEvent describes a gesture recognized by a GestureReader. Code is
+// the key responsible for single-key gestures; Keys holds the keys down
+// when the gesture was recognized.
+type GestureEvent struct {
+	Gesture Gesture
+	Code    KeyCode
+	Keys    KeySet
+}
+
+// GestureReader recognizes gestures from the key events returned by a
+// KeyReader. Keys in Repeat generate GestureRepeat events while held;
+// all other keys generate GestureLongPress once held for LongPress.
+// Clicks are delayed by up to DoubleClick to detect double clicks. A
+// zero duration disables the corresponding gesture.
+//
+// A GestureReader is not safe for concurrent use, except that Close and
+// Wake may be called while another goroutine is waiting in Next.
+type GestureReader struct {
+	LongPress   time.Duration
+	DoubleClick time.Duration
+	RepeatDelay time.Duration
+	RepeatRate  time.Duration
+	Repeat      KeySet
+
+	r     KeyReader
+	keys  <-chan keyResult
+	err   error
+	queue []GestureEvent
+	done  chan struct{}
+	once  sync.Once
+	wake  chan struct{}
+
+	down    KeySet
+	chorded bool
+
+	held       bool
+	heldCode   KeyCode
+	heldAt     time.Time
+	fired      bool
+	nextRepeat time.Time
+
+	pending     bool
+	pendingCode KeyCode
+	pendingAt   time.Time
+}
+
+func NewGestureReader(r KeyReader) *GestureReader {
+	return &GestureReader{
+		LongPress:   time.Second,
+		RepeatDelay: 500 * time.Millisecond,
+		RepeatRate:  150 * time.Millisecond,
+		Repeat:      Keys(KeyUp, KeyDown),
+		r:           r,
+		done:        make(chan struct{}),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Wake causes a pending call to Next or NextTimeout, or the next call if
+// none is pending, to return ErrTimeout. It may be called concurrently
+// with Next, allowing another goroutine to interrupt a reader waiting
+// for input.
+func (g *GestureReader) Wake() {
+	select {
+	case g.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops reading key events from the underlying KeyReader. Pending
+// and subsequent calls to Next return ErrClosed.
+func (g *GestureReader) Close() {
+	g.once.Do(func() { close(g.done) })
+}
+
+// Down returns the set of keys currently held.
+func (g *GestureReader) Down() KeySet {
+	return g.down
+}
+
+func (g *GestureReader) Next() (GestureEvent, error) {
+	return g.next(time.Time{})
+}
+
+// NextTimeout is like Next, but returns ErrTimeout if no gesture is
+// recognized within d.
+func (g *GestureReader) NextTimeout(d time.Duration) (GestureEvent, error) {
+	return g.next(time.Now().Add(d))
+}
+
+func (g *GestureReader) next(deadline time.Time) (e GestureEvent, err error) {
+	if g.keys == nil {
+		g.keys = readKeys(g.r, g.done)
+	}
+	for {
+		if len(g.queue) > 0 {
+			e, g.queue = g.queue[0], g.queue[1:]
+			return
+		}
+		if g.err != nil {
+			return e, g.err
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if t := g.wakeup(deadline); !t.IsZero() {
+			timer = time.NewTimer(time.Until(t))
+			timeout = timer.C
+		}
+		select {
+		case <-g.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			g.err = ErrClosed
+		case <-g.wake:
+			if timer != nil {
+				timer.Stop()
+			}
+			return e, ErrTimeout
+		case r := <-g.keys:
+			if timer != nil {
+				timer.Stop()
+			}
+			if r.err != nil {
+				g.err = r.err
+				continue
+			}
+			g.handle(r.key, time.Now())
+		case now := <-timeout:
+			g.expire(now)
+			if !deadline.IsZero() && !now.Before(deadline) && len(g.queue) == 0 {
+				return e, ErrTimeout
+			}
+		}
+	}
+}
+
+func (g *GestureReader) wakeup(deadline time.Time) (t time.Time) {
+	earliest := func(v time.Time) {
+		if t.IsZero() || v.Before(t) {
+			t = v
+		}
+	}
+	if !deadline.IsZero() {
+		earliest(deadline)
+	}
+	if g.pending {
+		earliest(g.pendingAt.Add(g.DoubleClick))
+	}
+	if g.held {
+		switch {
+		case g.repeats(g.heldCode):
+			earliest(g.nextRepeat)
+		case !g.fired && g.LongPress > 0:
+			earliest(g.heldAt.Add(g.LongPress))
+		}
+	}
+	return
+}
+
+func (g *GestureReader) repeats(code KeyCode) bool {
+	return g.Repeat.Has(code) && g.RepeatDelay > 0
+}
+
+func (g *GestureReader) emit(gesture Gesture, code KeyCode) {
+	g.queue = append(g.queue, GestureEvent{gesture, code, g.down})
+}
+
+func (g *GestureReader) flush() {
+	if g.pending {
+		g.pending = false
+		g.emit(GestureClick, g.pendingCode)
+	}
+}
+
+func (g *GestureReader) handle(key Key, now time.Time) {
+	switch key.Event {
+	case KeyPress:
+		if g.down.Has(key.Code) {
+			return // already held
+		}
+		g.down |= Keys(key.Code)
+		if g.pending && g.pendingCode != key.Code {
+			g.flush()
+		}
+		if g.down.Len() > 1 {
+			g.flush()
+			g.held, g.chorded = false, true
+			g.emit(GestureChord, key.Code)
+			return
+		}
+		g.held, g.heldCode, g.heldAt = true, key.Code, now
+		g.fired = false
+		g.nextRepeat = now.Add(g.RepeatDelay)
+
+	case KeyRelease:
+		if !g.down.Has(key.Code) {
+			return // not held
+		}
+		g.down &^= Keys(key.Code)
+		if g.chorded {
+			g.chorded = g.down != 0
+			return
+		}
+		if !g.held || g.heldCode != key.Code {
+			return
+		}
+		g.held = false
+		if g.fired {
+			return
+		}
+		switch {
+		case g.DoubleClick <= 0:
+			g.emit(GestureClick, key.Code)
+		case g.pending && now.Sub(g.pendingAt) <= g.DoubleClick:
+			g.pending = false
+			g.emit(GestureDoubleClick, key.Code)
+		default:
+			g.pending, g.pendingCode, g.pendingAt = true, key.Code, now
+		}
+	}
+}
+
+func (g *GestureReader) expire(now time.Time) {
+	if g.pending && !now.Before(g.pendingAt.Add(g.DoubleClick)) {
+		g.flush()
+	}
+	if !g.held {
+		return
+	}
+	switch {
+	case g.repeats(g.heldCode):
+		if !now.Before(g.nextRepeat) {
+			rate := g.RepeatRate
+			if rate <= 0 {
+				rate = g.RepeatDelay
+			}
+			g.fired = true
+			g.nextRepeat = now.Add(rate)
+			g.emit(GestureRepeat, g.heldCode)
+		}
+	case !g.fired && g.LongPress > 0:
+		if !now.Before(g.heldAt.Add(g.LongPress)) {
+			g.fired = true
+			g.emit(GestureLongPress, g.heldCode)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+// Key input reports recorded from a display. The key code is followed by
+// the event: 01 when pressed and 00 when released.
+const (
+	upPress       = "aa 03 00 01 00 00 00 00 00 00 00 00 00 00 00 52"
+	upRelease     = "aa 03 00 00 00 00 00 00 00 00 00 00 00 00 00 53"
+	enterPress    = "aa 03 04 01 00 00 00 00 00 00 00 00 00 00 00 4e"
+	enterRelease  = "aa 03 04 00 00 00 00 00 00 00 00 00 00 00 00 4f"
+	cancelPress   = "aa 03 05 01 00 00 00 00 00 00 00 00 00 00 00 4d"
+	cancelRelease = "aa 03 05 00 00 00 00 00 00 00 00 00 00 00 00 4e"
+)
+
+func TestGetInputRecorded(t *testing.T) {
+	tests := []struct {
+		report string
+		want   Key
+	}{
+		{upPress, Key{KeyUp, KeyPress}},
+		{upRelease, Key{KeyUp, KeyRelease}},
+		{enterPress, Key{KeyEnter, KeyPress}},
+		{cancelRelease, Key{KeyCancel, KeyRelease}},
+	}
+	for _, tt := range tests {
+		l := New(newFakeDevice(fakehid.Report{At: 0, Report: tt.report}))
+		key, err := l.GetInput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != tt.want {
+			t.Errorf("GetInput() = %v, want %v for %s", key, tt.want, tt.report)
+		}
+	}
+}
+
+func TestGestureReader(t *testing.T) {
+	const ms = time.Millisecond
+	tests := []struct {
+		name   string
+		script []fakehid.Report
+		want   []GestureEvent
+	}{
+		{
+			name: "click",
+			script: []fakehid.Report{
+				{At: 10 * ms, Report: enterPress},
+				{At: 30 * ms, Report: enterRelease},
+			},
+			want: []GestureEvent{
+				{GestureClick, KeyEnter, 0},
+			},
+		},
+		{
+			name: "long press",
+			script: []fakehid.Report{
+				{At: 10 * ms, Report: enterPress},
+				{At: 300 * ms, Report: enterRelease},
+			},
+			want: []GestureEvent{
+				{GestureLongPress, KeyEnter, Keys(KeyEnter)},
+			},
+		},
+		{
+			name: "repeat",
+			script: []fakehid.Report{
+				{At: 10 * ms, Report: upPress},
+				{At: 260 * ms, Report: upRelease},
+			},
+			want: []GestureEvent{
+				{GestureRepeat, KeyUp, Keys(KeyUp)},
+				{GestureRepeat, KeyUp, Keys(KeyUp)},
+			},
+		},
+		{
+			name: "chord",
+			script: []fakehid.Report{
+				{At: 10 * ms, Report: enterPress},
+				{At: 30 * ms, Report: cancelPress},
+				{At: 50 * ms, Report: cancelRelease},
+				{At: 60 * ms, Report: enterRelease},
+			},
+			want: []GestureEvent{
+				{GestureChord, KeyCancel, Keys(KeyEnter, KeyCancel)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGestureReader(New(newFakeDevice(tt.script...)))
+			defer g.Close()
+			g.LongPress = 150 * ms
+			g.RepeatDelay = 100 * ms
+			g.RepeatRate = 100 * ms
+
+			var got []GestureEvent
+			for {
+				e, err := g.NextTimeout(400 * ms)
+				if err == ErrTimeout {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, e)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("event %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGestureReaderClose(t *testing.T) {
+	g := NewGestureReader(New(newFakeDevice()))
+	errc := make(chan error)
+	go func() {
+		_, err := g.Next()
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	g.Close()
+	select {
+	case err := <-errc:
+		if err != ErrClosed {
+			t.Errorf("Next() error = %v, want %v", err, ErrClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Next() did not return after Close")
+	}
+}
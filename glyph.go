@@ -0,0 +1,122 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"errors"
+)
+
+// GlyphSlots is the number of custom glyphs held by the display.
+const GlyphSlots = 8
+
+// ErrNoGlyphSlots is returned when all glyph slots are in use.
+var ErrNoGlyphSlots = errors.New("glyph: no free slots")
+
+// Glyph is a 5x8 custom character. Each byte holds one row from top to
+// bottom, with the low five bits holding pixels from left to right.
+type Glyph [8]byte
+
+type glyphSlot struct {
+	g      Glyph
+	refs   int
+	loaded bool
+}
+
+// SetGlyph programs slot with g. Custom glyphs are displayed by writing
+// the slot number to a cell; see DrawFrame.
+func (l *LCD) SetGlyph(slot int, g Glyph) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.setGlyph(slot, g)
+}
+
+func (l *LCD) setGlyph(slot int, g Glyph) (err error) {
+	if slot < 0 || slot >= GlyphSlots {
+		return errors.New("glyph: invalid slot")
+	}
+	b := []byte{pLCD, pControl, pCGRAM + byte(slot)*byte(len(g))}
+	if err = l.sendOutputReport(b); err != nil {
+		return
+	}
+	b = append([]byte{pLCD, pWrite}, g[:]...)
+	if err = l.sendOutputReport(b); err != nil {
+		return
+	}
+	l.glyphs[slot] = glyphSlot{g: g, refs: l.glyphs[slot].refs, loaded: true}
+	return l.restoreCursor(l.pos)
+}
+
+// glyphCells is like printable, but preserves custom glyphs.
+func glyphCells(p []byte) []byte {
+	b := printable(p)
+	for i, c := range p {
+		if c < GlyphSlots {
+			b[i] = c
+		}
+	}
+	return b
+}
+
+// AllocGlyph returns a slot holding g, programming an unused slot if g
+// is not already loaded. Slots are reference counted and should be
+// released with FreeGlyph once no longer displayed.
+func (l *LCD) AllocGlyph(g Glyph) (slot byte, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.allocGlyph(g)
+}
+
+func (l *LCD) allocGlyph(g Glyph) (byte, error) {
+	var free = -1
+	for i, s := range l.glyphs {
+		switch {
+		case s.loaded && s.g == g:
+			l.glyphs[i].refs++
+			return byte(i), nil
+		case s.refs == 0 && (free < 0 || !s.loaded):
+			free = i
+		}
+	}
+	if free < 0 {
+		return 0, ErrNoGlyphSlots
+	}
+	if err := l.setGlyph(free, g); err != nil {
+		return 0, err
+	}
+	l.glyphs[free].refs = 1
+	return byte(free), nil
+}
+
+// FreeGlyph releases a slot returned by AllocGlyph.
+func (l *LCD) FreeGlyph(slot byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.freeGlyph(slot)
+}
+
+func (l *LCD) freeGlyph(slot byte) {
+	if int(slot) < GlyphSlots && l.glyphs[slot].refs > 0 {
+		l.glyphs[slot].refs--
+	}
+}
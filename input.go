@@ -0,0 +1,98 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrTimeout is returned when no input is received before a timeout expires.
+var ErrTimeout = errors.New("timeout")
+
+// ErrClosed is returned when reading from a closed reader.
+var ErrClosed = errors.New("closed")
+
+// KeyReader is the interface that wraps the GetInput method. It is
+// implemented by LCD and by the filters layered on top of it.
+type KeyReader interface {
+	GetInput() (Key, error)
+}
+
+// KeySet is a set of key codes.
+type KeySet byte
+
+func Keys(codes ...KeyCode) (s KeySet) {
+	for _, code := range codes {
+		s |= 1 << code
+	}
+	return
+}
+
+func (s KeySet) Has(code KeyCode) bool {
+	return s&(1<<code) != 0
+}
+
+func (s KeySet) Len() (n int) {
+	for ; s != 0; s &= s - 1 {
+		n++
+	}
+	return
+}
+
+func (s KeySet) String() string {
+	var names []string
+	for code := KeyUp; code <= KeyCancel; code++ {
+		if s.Has(code) {
+			names = append(names, code.String())
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+type keyResult struct {
+	key Key
+	err error
+}
+
+// readKeys reads key events from r until an error occurs or done is
+// closed. Once done is closed, the goroutine exits as soon as the call to
+// GetInput in progress returns; the key it returns is discarded.
+func readKeys(r KeyReader, done <-chan struct{}) <-chan keyResult {
+	c := make(chan keyResult)
+	go func() {
+		for {
+			key, err := r.GetInput()
+			select {
+			case c <- keyResult{key, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return c
+}
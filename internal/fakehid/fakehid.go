@@ -0,0 +1,315 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package fakehid emulates an SMC LCD HID device for tests.
+//
+// A Device interprets output reports as the display would: it keeps the
+// contents of display and character generator RAM, the cursor, and the
+// backlight, and answers version and read requests with input reports.
+// Key events may be scripted ahead of time or injected with Key.
+//
+// The package does not import smclcd so that tests of the smclcd package
+// itself may use it; the error returned when no input report arrives is
+// supplied by the caller instead.
+package fakehid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Lines and Columns give the size of the visible display.
+	Lines   = 2
+	Columns = 16
+
+	reportLen      = 16
+	inputReportID  = 0xaa
+	outputReportID = 0xbb
+	dataLen        = reportLen - 4 // data bytes in read and write reports
+	lineLen        = 40            // display RAM addresses per line
+	lineAddr       = 0x40          // display RAM address of line 1
+)
+
+// Version is the firmware version reported by a Device.
+var Version = [2]byte{1, 0}
+
+// Report is an input report returned by a Device once At has elapsed
+// since the Device was created. Report is written in hex; spaces are
+// ignored.
+type Report struct {
+	At     time.Duration
+	Report string
+}
+
+// Device emulates a display. Its methods are safe for concurrent use.
+type Device struct {
+	timeout error
+	closed  chan struct{}
+	once    sync.Once
+	input   chan []byte
+
+	mu     sync.Mutex
+	start  time.Time
+	script []Report
+
+	wmu       sync.Mutex
+	written   []byte
+	ddram     [Lines][lineLen]byte
+	cgram     [64]byte
+	addr      byte // address counter
+	cgAddr    bool // address counter refers to character generator RAM
+	cursor    byte
+	backlight byte
+}
+
+// New returns a Device that returns script in order. ReadWithTimeout
+// returns timeout if no report is due before the timeout elapses.
+func New(timeout error, script ...Report) *Device {
+	d := &Device{
+		timeout: timeout,
+		closed:  make(chan struct{}),
+		input:   make(chan []byte, 64),
+		start:   time.Now(),
+		script:  script,
+	}
+	d.clear()
+	return d
+}
+
+func (d *Device) clear() {
+	for i := range d.ddram {
+		for j := range d.ddram[i] {
+			d.ddram[i][j] = ' '
+		}
+	}
+	d.addr, d.cgAddr = 0, false
+}
+
+// Write interprets an output report. Malformed reports are ignored, as
+// the display would.
+func (d *Device) Write(p []byte) (int, error) {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	if len(p) != reportLen || p[0] != outputReportID || sum(p) != 0 {
+		return len(p), nil
+	}
+	switch {
+	case p[1] == 0x01: // version
+		d.reply(0x01, Version[:])
+	case p[1] == 0x02 && p[2] == 0x00: // control
+		d.control(p[3])
+	case p[1] == 0x02 && p[2] == 0x02: // write
+		data := bytes.TrimRight(p[3:len(p)-1], "\x00")
+		d.written = append(d.written, data...)
+		if d.cgAddr {
+			// Glyph rows may be zero; fill the remainder of the glyph.
+			data = p[3 : 3+int(8-d.addr%8)]
+		}
+		for _, c := range data {
+			d.put(c)
+		}
+	case p[1] == 0x02 && p[2] == 0x03: // read
+		b := make([]byte, dataLen)
+		for i := range b {
+			b[i] = d.get()
+		}
+		d.reply(0x02, append([]byte{0x03}, b...))
+	case p[1] == 0x07: // backlight
+		d.backlight = p[2]
+	}
+	return len(p), nil
+}
+
+func (d *Device) control(c byte) {
+	switch {
+	case c == 0x01:
+		d.clear()
+	case c == 0x02:
+		d.addr, d.cgAddr = 0, false
+	case c >= 0x0c && c <= 0x0f:
+		d.cursor = c - 0x0c
+	case c >= 0x40 && c < 0x80:
+		d.addr, d.cgAddr = c-0x40, true
+	case c >= 0x80:
+		d.addr, d.cgAddr = c-0x80, false
+	}
+}
+
+// cell returns the display RAM cell at the address counter, if any.
+func (d *Device) cell() *byte {
+	y, x := int(d.addr/lineAddr), int(d.addr%lineAddr)
+	if y >= Lines || x >= lineLen {
+		return nil
+	}
+	return &d.ddram[y][x]
+}
+
+func (d *Device) put(c byte) {
+	if d.cgAddr {
+		d.cgram[d.addr%64] = c & 0x1f
+	} else if p := d.cell(); p != nil {
+		*p = c
+	}
+	d.advance()
+}
+
+func (d *Device) get() (c byte) {
+	if d.cgAddr {
+		c = d.cgram[d.addr%64]
+	} else if p := d.cell(); p != nil {
+		c = *p
+	}
+	d.advance()
+	return
+}
+
+// advance increments the address counter, moving from the end of one
+// line to the start of the other.
+func (d *Device) advance() {
+	switch d.addr++; {
+	case d.cgAddr:
+		d.addr %= 64
+	case d.addr == lineLen:
+		d.addr = lineAddr
+	case d.addr == lineAddr+lineLen:
+		d.addr = 0
+	}
+}
+
+func (d *Device) reply(cmd byte, data []byte) {
+	b := make([]byte, reportLen)
+	b[0], b[1] = inputReportID, cmd
+	copy(b[2:len(b)-1], data)
+	b[len(b)-1] = -sum(b)
+	select {
+	case d.input <- b:
+	default:
+	}
+}
+
+func sum(p []byte) (v byte) {
+	for _, c := range p {
+		v += c
+	}
+	return
+}
+
+// Key sends a key input report with code and event, as if a key on the
+// front panel was pressed or released.
+func (d *Device) Key(code, event byte) {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	d.reply(0x03, []byte{code, event})
+}
+
+// Input queues a raw input report, which need not be valid.
+func (d *Device) Input(report []byte) {
+	d.input <- append([]byte(nil), report...)
+}
+
+// ReadWithTimeout returns the next input report. Replies and injected
+// key events are returned before scripted reports.
+func (d *Device) ReadWithTimeout(p []byte, timeout time.Duration) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	select {
+	case b := <-d.input:
+		return copy(p, b), nil
+	default:
+	}
+	if len(d.script) > 0 {
+		r := d.script[0]
+		if wait := time.Until(d.start.Add(r.At)); wait <= timeout {
+			time.Sleep(wait)
+			d.script = d.script[1:]
+			b, err := hex.DecodeString(strings.ReplaceAll(r.Report, " ", ""))
+			if err != nil {
+				panic(err)
+			}
+			return copy(p, b), nil
+		}
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case b := <-d.input:
+		return copy(p, b), nil
+	case <-timer.C:
+		return 0, d.timeout
+	case <-d.closed:
+		return 0, os.ErrClosed
+	}
+}
+
+// Close causes pending and future reads to fail.
+func (d *Device) Close() error {
+	d.once.Do(func() { close(d.closed) })
+	return nil
+}
+
+// Written returns a copy of the characters written so far.
+func (d *Device) Written() []byte {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	return append([]byte(nil), d.written...)
+}
+
+// Text returns the visible contents of the display, line by line.
+func (d *Device) Text() string {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	var b []byte
+	for i := range d.ddram {
+		b = append(b, d.ddram[i][:Columns]...)
+	}
+	return string(b)
+}
+
+// Cursor returns the cursor position and the state last set, numbered as
+// smclcd.Cursor. The position is undefined while character generator RAM
+// is addressed.
+func (d *Device) Cursor() (y, x int, state byte) {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	return int(d.addr / lineAddr), int(d.addr % lineAddr), d.cursor
+}
+
+// Glyph returns the 8 rows of the custom glyph in slot.
+func (d *Device) Glyph(slot int) (g [8]byte) {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	copy(g[:], d.cgram[slot*8:])
+	return
+}
+
+// Backlight reports whether the backlight is on.
+func (d *Device) Backlight() bool {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	return d.backlight != 0
+}
@@ -0,0 +1,493 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package lcdproc implements the LCDd client protocol, allowing clients
+// written for LCDproc to share the display.
+//
+// Clients add screens containing widgets; the screen shown is chosen by
+// priority, rotating between screens of equal priority. The string,
+// title, hbar, vbar, icon, num, and scroller widgets are supported, as
+// are key reservations and backlight control. Frames and menus are not
+// supported.
+package lcdproc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// DefaultAddr is the address LCDd listens on by default.
+const DefaultAddr = "127.0.0.1:13666"
+
+// tick is the unit of time used by the protocol.
+const tick = time.Second / 8
+
+const (
+	writeTimeout = 5 * time.Second
+	maxQueued    = 64 // responses and events queued per client
+)
+
+var keyNames = map[smclcd.KeyCode]string{
+	smclcd.KeyUp:     "Up",
+	smclcd.KeyDown:   "Down",
+	smclcd.KeyLeft:   "Left",
+	smclcd.KeyRight:  "Right",
+	smclcd.KeyEnter:  "Enter",
+	smclcd.KeyCancel: "Escape",
+}
+
+// Server serves LCDproc clients. Errors updating the display are logged
+// to ErrorLog, or if nil, the standard logger.
+type Server struct {
+	LCD      *smclcd.LCD
+	ErrorLog *log.Logger
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	screens []*screen
+	once    sync.Once
+
+	// render state
+	cur    *screen
+	shown  int
+	ticks  int
+	frame  *smclcd.Frame
+	slots  []byte
+	light  smclcd.Backlight
+	cursor smclcd.Cursor
+	pos    int
+	init   bool
+}
+
+type client struct {
+	c         net.Conn
+	out       chan string
+	done      chan struct{}
+	hello     bool
+	name      string
+	backlight string
+	screens   map[string]*screen
+	keys      map[string]bool // true if reserved exclusively
+}
+
+func NewServer(l *smclcd.LCD) *Server {
+	return &Server{
+		LCD:     l,
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// Serve accepts connections on ln until an error occurs.
+func (s *Server) Serve(ln net.Listener) error {
+	s.once.Do(func() {
+		go s.run()
+		go s.pumpKeys()
+	})
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(c)
+	}
+}
+
+func (s *Server) serveConn(c net.Conn) {
+	cl := &client{
+		c:         c,
+		out:       make(chan string, maxQueued),
+		done:      make(chan struct{}),
+		backlight: "open",
+		screens:   make(map[string]*screen),
+		keys:      make(map[string]bool),
+	}
+	s.mu.Lock()
+	s.clients[cl] = struct{}{}
+	s.mu.Unlock()
+
+	written := make(chan struct{})
+	go func() {
+		cl.write()
+		close(written)
+	}()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, cl)
+		for _, sc := range cl.screens {
+			s.removeScreen(sc)
+		}
+		s.mu.Unlock()
+		close(cl.done)
+		<-written
+		c.Close()
+	}()
+
+	r := bufio.NewScanner(c)
+	for r.Scan() {
+		args, err := splitArgs(r.Text())
+		if err == nil && len(args) == 0 {
+			continue
+		}
+		if err == nil && args[0] == "bye" {
+			return
+		}
+		var resp string
+		if err == nil {
+			s.mu.Lock()
+			resp, err = s.exec(cl, args)
+			s.mu.Unlock()
+		}
+		if err != nil {
+			resp = "huh? " + err.Error()
+		}
+		cl.send(resp)
+	}
+}
+
+// send queues s to be written to the client. It does not block, so it may
+// be called while holding the server lock; a client that falls too far
+// behind is disconnected.
+func (cl *client) send(s string) {
+	select {
+	case cl.out <- s:
+	default:
+		cl.c.Close()
+	}
+}
+
+// write writes queued lines to the client until done is closed, then
+// flushes any that remain.
+func (cl *client) write() {
+	for {
+		var s string
+		select {
+		case s = <-cl.out:
+		case <-cl.done:
+			select {
+			case s = <-cl.out:
+			default:
+				return
+			}
+		}
+		cl.c.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := cl.c.Write([]byte(s + "\n")); err != nil {
+			cl.c.Close()
+			return
+		}
+	}
+}
+
+func (s *Server) logf(format string, v ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+func (s *Server) pumpKeys() {
+	for {
+		key, err := s.LCD.GetInput()
+		if err != nil {
+			return
+		}
+		name, ok := keyNames[key.Code]
+		if !ok || key.Event != smclcd.KeyPress {
+			continue
+		}
+
+		s.mu.Lock()
+		var dest *client
+		for cl := range s.clients {
+			if cl.keys[name] {
+				dest = cl
+			}
+		}
+		if dest == nil && s.cur != nil {
+			if _, ok := s.cur.cl.keys[name]; ok {
+				dest = s.cur.cl
+			}
+		}
+		s.mu.Unlock()
+
+		if dest != nil {
+			dest.send("key " + name)
+		}
+	}
+}
+
+func (s *Server) exec(cl *client, args []string) (string, error) {
+	cmd, args := args[0], args[1:]
+	if cmd == "hello" {
+		cl.hello = true
+		return fmt.Sprintf("connect LCDproc 0.5.9 protocol 0.3 lcd wid %d hgt %d cellwid 5 cellhgt 8",
+			smclcd.Columns, smclcd.Lines), nil
+	}
+	if !cl.hello {
+		return "", errors.New("Please send hello first")
+	}
+
+	switch cmd {
+	case "client_set":
+		opts, err := parseOpts(args)
+		if err != nil {
+			return "", err
+		}
+		for k, v := range opts {
+			if k != "name" {
+				return "", errors.New("invalid option -" + k)
+			}
+			cl.name = v
+		}
+	case "client_add_key":
+		var excl bool
+		if len(args) > 0 && strings.HasPrefix(args[0], "-") {
+			switch args[0] {
+			case "-exclusively":
+				excl = true
+			case "-shared":
+			default:
+				return "", errors.New("invalid option " + args[0])
+			}
+			args = args[1:]
+		}
+		for _, k := range args {
+			if !isKey(k) {
+				return "", errors.New("invalid key " + k)
+			}
+			for other := range s.clients {
+				if e, ok := other.keys[k]; ok && other != cl && (e || excl) {
+					return "", errors.New("could not reserve key " + k)
+				}
+			}
+		}
+		for _, k := range args {
+			cl.keys[k] = excl
+		}
+	case "client_del_key":
+		for _, k := range args {
+			delete(cl.keys, k)
+		}
+	case "screen_add":
+		if len(args) != 1 {
+			return "", errors.New("usage: screen_add <screenid>")
+		}
+		if _, ok := cl.screens[args[0]]; ok {
+			return "", errors.New("screen already exists")
+		}
+		sc := newScreen(cl, args[0])
+		cl.screens[sc.id] = sc
+		s.screens = append(s.screens, sc)
+	case "screen_del":
+		if len(args) != 1 {
+			return "", errors.New("usage: screen_del <screenid>")
+		}
+		sc, err := cl.screen(args[0])
+		if err != nil {
+			return "", err
+		}
+		s.removeScreen(sc)
+	case "screen_set":
+		if len(args) < 1 {
+			return "", errors.New("usage: screen_set <screenid> [-option value]...")
+		}
+		sc, err := cl.screen(args[0])
+		if err != nil {
+			return "", err
+		}
+		opts, err := parseOpts(args[1:])
+		if err != nil {
+			return "", err
+		}
+		if err = sc.set(opts); err != nil {
+			return "", err
+		}
+	case "widget_add":
+		if len(args) < 3 {
+			return "", errors.New("usage: widget_add <screenid> <widgetid> <widgettype>")
+		}
+		sc, err := cl.screen(args[0])
+		if err != nil {
+			return "", err
+		}
+		if len(args) > 3 {
+			return "", errors.New("frames are not supported")
+		}
+		if err = sc.addWidget(args[1], args[2]); err != nil {
+			return "", err
+		}
+	case "widget_del":
+		if len(args) != 2 {
+			return "", errors.New("usage: widget_del <screenid> <widgetid>")
+		}
+		sc, err := cl.screen(args[0])
+		if err != nil {
+			return "", err
+		}
+		if err = sc.delWidget(args[1]); err != nil {
+			return "", err
+		}
+	case "widget_set":
+		if len(args) < 2 {
+			return "", errors.New("usage: widget_set <screenid> <widgetid> <params...>")
+		}
+		sc, err := cl.screen(args[0])
+		if err != nil {
+			return "", err
+		}
+		w, err := sc.widget(args[1])
+		if err != nil {
+			return "", err
+		}
+		if err = w.set(args[2:]); err != nil {
+			return "", err
+		}
+	case "backlight":
+		if len(args) != 1 {
+			return "", errors.New("usage: backlight {on|off|toggle|blink|flash}")
+		}
+		b, err := parseBacklight(args[0], cl.backlight)
+		if err != nil {
+			return "", err
+		}
+		cl.backlight = b
+	case "info":
+		v, err := s.LCD.Version()
+		if err != nil {
+			return "", err
+		}
+		return "SuperMicro LCD " + v, nil
+	case "output", "noop", "sleep":
+	default:
+		return "", errors.New("invalid command " + cmd)
+	}
+	return "success", nil
+}
+
+func isKey(name string) bool {
+	for _, k := range keyNames {
+		if k == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (cl *client) screen(id string) (*screen, error) {
+	sc, ok := cl.screens[id]
+	if !ok {
+		return nil, errors.New("invalid screen id " + id)
+	}
+	return sc, nil
+}
+
+func (s *Server) removeScreen(sc *screen) {
+	delete(sc.cl.screens, sc.id)
+	for i, other := range s.screens {
+		if other == sc {
+			s.screens = append(s.screens[:i], s.screens[i+1:]...)
+			break
+		}
+	}
+	if s.cur == sc {
+		s.cur = nil
+	}
+}
+
+// splitArgs splits a command line into arguments. Arguments are
+// separated by spaces, and may be quoted using double quotes or braces;
+// a backslash escapes the following character.
+func splitArgs(line string) (args []string, err error) {
+	var b strings.Builder
+	var quote rune
+	var inArg bool
+	var escape bool
+	for _, r := range line {
+		switch {
+		case escape:
+			b.WriteRune(r)
+			escape = false
+		case r == '\\':
+			escape, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '"':
+			quote, inArg = '"', true
+		case r == '{':
+			quote, inArg = '}', true
+		case r == ' ' || r == '\t' || r == '\r':
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escape {
+		return nil, errors.New("unterminated argument")
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return
+}
+
+// parseOpts parses options of the form -name value.
+func parseOpts(args []string) (map[string]string, error) {
+	opts := make(map[string]string)
+	for i := 0; i < len(args); i += 2 {
+		if !strings.HasPrefix(args[i], "-") || i+1 == len(args) {
+			return nil, errors.New("invalid option " + args[i])
+		}
+		opts[args[i][1:]] = args[i+1]
+	}
+	return opts, nil
+}
+
+func parseInts(args []string, v ...*int) (err error) {
+	if len(args) != len(v) {
+		return errors.New("wrong number of parameters")
+	}
+	for i := range v {
+		if *v[i], err = strconv.Atoi(args[i]); err != nil {
+			return errors.New("invalid number " + args[i])
+		}
+	}
+	return
+}
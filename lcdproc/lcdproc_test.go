@@ -0,0 +1,94 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package lcdproc
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+func TestLoopback(t *testing.T) {
+	d := fakehid.New(smclcd.ErrTimeout)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go NewServer(smclcd.New(d)).Serve(ln)
+
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(c)
+
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"screen_add s", "huh? Please send hello first"},
+		{"hello", "connect LCDproc 0.5.9 protocol 0.3 lcd wid 16 hgt 2 cellwid 5 cellhgt 8"},
+		{"client_set -name test", "success"},
+		{"screen_add s", "success"},
+		{"screen_add s", "huh? screen already exists"},
+		{"widget_add s w string", "success"},
+		{`widget_set s w 1 2 "Hello, world"`, "success"},
+		{"bogus", "huh? invalid command bogus"},
+	}
+	for _, tt := range tests {
+		if _, err := c.Write([]byte(tt.cmd + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		// Skip listen and ignore events sent as screens change.
+		var line string
+		for {
+			if line, err = r.ReadString('\n'); err != nil {
+				t.Fatal(err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if !strings.HasPrefix(line, "listen ") && !strings.HasPrefix(line, "ignore ") {
+				break
+			}
+		}
+		if line != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.cmd, line, tt.want)
+		}
+	}
+
+	for deadline := time.Now().Add(2 * time.Second); !bytes.Contains(d.Written(), []byte("Hello, world")); {
+		if time.Now().After(deadline) {
+			t.Fatal("widget not drawn")
+		}
+		time.Sleep(tick)
+	}
+}
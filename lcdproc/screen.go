@@ -0,0 +1,614 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package lcdproc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// Screen priorities, from lowest to highest.
+const (
+	priorityHidden = iota
+	priorityBackground
+	priorityInfo
+	priorityForeground
+	priorityAlert
+	priorityInput
+)
+
+var priorities = map[string]int{
+	"hidden":     priorityHidden,
+	"background": priorityBackground,
+	"info":       priorityInfo,
+	"foreground": priorityForeground,
+	"alert":      priorityAlert,
+	"input":      priorityInput,
+}
+
+var cursors = map[string]smclcd.Cursor{
+	"off":   smclcd.CursorOff,
+	"on":    smclcd.CursorBoth,
+	"under": smclcd.CursorUnderline,
+	"block": smclcd.CursorBlock,
+}
+
+type screen struct {
+	cl        *client
+	id        string
+	name      string
+	priority  int
+	duration  int // in ticks
+	timeout   int // in ticks; negative if none
+	backlight string
+	cursor    smclcd.Cursor
+	cursorX   int
+	cursorY   int
+	widgets   []*widget
+}
+
+func newScreen(cl *client, id string) *screen {
+	return &screen{
+		cl:        cl,
+		id:        id,
+		name:      id,
+		priority:  priorityInfo,
+		duration:  32,
+		timeout:   -1,
+		backlight: "open",
+		cursorX:   1,
+		cursorY:   1,
+	}
+}
+
+func (sc *screen) set(opts map[string]string) (err error) {
+	for k, v := range opts {
+		switch k {
+		case "name":
+			sc.name = v
+		case "wid", "hgt", "heartbeat":
+			// ignored
+		case "priority":
+			if sc.priority, err = parsePriority(v); err != nil {
+				return
+			}
+		case "backlight":
+			if sc.backlight, err = parseBacklight(v, sc.backlight); err != nil {
+				return
+			}
+		case "duration", "timeout", "cursor_x", "cursor_y":
+			var n int
+			if n, err = strconv.Atoi(v); err != nil {
+				return errors.New("invalid number " + v)
+			}
+			switch k {
+			case "duration":
+				sc.duration = n
+			case "timeout":
+				sc.timeout = n
+			case "cursor_x":
+				sc.cursorX = n
+			case "cursor_y":
+				sc.cursorY = n
+			}
+		case "cursor":
+			c, ok := cursors[v]
+			if !ok {
+				return errors.New("invalid cursor " + v)
+			}
+			sc.cursor = c
+		default:
+			return errors.New("invalid option -" + k)
+		}
+	}
+	return
+}
+
+func parsePriority(s string) (int, error) {
+	if p, ok := priorities[s]; ok {
+		return p, nil
+	}
+	// Numeric priorities are from earlier versions of the protocol,
+	// where lower values are more important.
+	n, err := strconv.Atoi(s)
+	switch {
+	case err != nil:
+		return 0, errors.New("invalid priority " + s)
+	case n <= 64:
+		return priorityForeground, nil
+	case n <= 192:
+		return priorityInfo, nil
+	default:
+		return priorityBackground, nil
+	}
+}
+
+func parseBacklight(s, cur string) (string, error) {
+	switch s {
+	case "on", "off", "blink", "flash", "open":
+		return s, nil
+	case "toggle":
+		if cur == "off" {
+			return "on", nil
+		}
+		return "off", nil
+	default:
+		return "", errors.New("invalid backlight state " + s)
+	}
+}
+
+func (sc *screen) addWidget(id, typ string) error {
+	switch typ {
+	case "string", "title", "hbar", "vbar", "icon", "scroller", "num":
+	default:
+		return errors.New("invalid widget type " + typ)
+	}
+	if _, err := sc.widget(id); err == nil {
+		return errors.New("widget already exists")
+	}
+	sc.widgets = append(sc.widgets, &widget{id: id, typ: typ})
+	return nil
+}
+
+func (sc *screen) delWidget(id string) error {
+	for i, w := range sc.widgets {
+		if w.id == id {
+			sc.widgets = append(sc.widgets[:i], sc.widgets[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("invalid widget id " + id)
+}
+
+func (sc *screen) widget(id string) (*widget, error) {
+	for _, w := range sc.widgets {
+		if w.id == id {
+			return w, nil
+		}
+	}
+	return nil, errors.New("invalid widget id " + id)
+}
+
+// widget coordinates are 1-based, as in the protocol.
+type widget struct {
+	id   string
+	typ  string
+	x, y int
+	n    int // bar length, number, or scroller speed
+	r, b int // scroller right and bottom
+	dir  string
+	text string
+}
+
+func (w *widget) set(args []string) error {
+	switch w.typ {
+	case "string":
+		if len(args) != 3 {
+			return errors.New("usage: widget_set <screenid> <widgetid> <x> <y> <text>")
+		}
+		w.text = args[2]
+		return parseInts(args[:2], &w.x, &w.y)
+	case "title":
+		if len(args) != 1 {
+			return errors.New("usage: widget_set <screenid> <widgetid> <text>")
+		}
+		w.text = args[0]
+	case "hbar", "vbar":
+		return parseInts(args, &w.x, &w.y, &w.n)
+	case "icon":
+		if len(args) != 3 {
+			return errors.New("usage: widget_set <screenid> <widgetid> <x> <y> <iconname>")
+		}
+		if _, ok := icons[args[2]]; !ok {
+			return errors.New("invalid icon " + args[2])
+		}
+		w.text = args[2]
+		return parseInts(args[:2], &w.x, &w.y)
+	case "num":
+		if err := parseInts(args, &w.x, &w.n); err != nil {
+			return err
+		}
+		if w.n < 0 || w.n > 10 {
+			return errors.New("invalid number " + args[1])
+		}
+	case "scroller":
+		if len(args) != 7 {
+			return errors.New("usage: widget_set <screenid> <widgetid> <left> <top> <right> <bottom> <direction> <speed> <text>")
+		}
+		switch args[4] {
+		case "h", "v", "m":
+			w.dir = args[4]
+		default:
+			return errors.New("invalid direction " + args[4])
+		}
+		w.text = args[6]
+		if err := parseInts(args[:4], &w.x, &w.y, &w.r, &w.b); err != nil {
+			return err
+		}
+		return parseInts(args[5:6], &w.n)
+	}
+	return nil
+}
+
+// cell is a character cell being rendered. If custom is set, g is drawn
+// using a glyph slot; c is used if no slot is available.
+type cell struct {
+	c      byte
+	g      smclcd.Glyph
+	custom bool
+}
+
+func glyphCell(g smclcd.Glyph, fallback byte) cell {
+	return cell{c: fallback, g: g, custom: true}
+}
+
+var icons = map[string]cell{
+	"BLOCK_FILLED":      {c: 0xff},
+	"ARROW_LEFT":        {c: 0x7f},
+	"ARROW_RIGHT":       {c: 0x7e},
+	"SELECTOR_AT_LEFT":  {c: 0x7e},
+	"SELECTOR_AT_RIGHT": {c: 0x7f},
+	"CHECKBOX_OFF":      {c: ' '},
+	"CHECKBOX_GRAY":     {c: '-'},
+	"HEART_OPEN":        glyphCell(smclcd.DefaultFont['♥'], '*'),
+	"HEART_FILLED":      glyphCell(smclcd.DefaultFont['♥'], '*'),
+	"ARROW_UP":          glyphCell(smclcd.DefaultFont['↑'], '^'),
+	"ARROW_DOWN":        glyphCell(smclcd.DefaultFont['↓'], 'v'),
+	"CHECKBOX_ON":       glyphCell(smclcd.DefaultFont['✓'], 'v'),
+	"ELLIPSIS":          glyphCell(smclcd.DefaultFont['…'], '.'),
+}
+
+type canvas [smclcd.Lines][smclcd.Columns]cell
+
+func newCanvas() (cv canvas) {
+	for y := range cv {
+		for x := range cv[y] {
+			cv[y][x].c = ' '
+		}
+	}
+	return
+}
+
+// put sets the cell at the 0-based position x, y, ignoring positions
+// outside of the display.
+func (cv *canvas) put(x, y int, c cell) {
+	if y >= 0 && y < smclcd.Lines && x >= 0 && x < smclcd.Columns {
+		cv[y][x] = c
+	}
+}
+
+func runeCell(r rune) cell {
+	if r >= ' ' && r < 0x7f {
+		return cell{c: byte(r)}
+	}
+	if g, ok := smclcd.DefaultFont[r]; ok {
+		return glyphCell(g, '?')
+	}
+	return cell{c: '?'}
+}
+
+func (cv *canvas) text(x, y int, s []rune) {
+	for i, r := range s {
+		cv.put(x+i, y, runeCell(r))
+	}
+}
+
+func (w *widget) draw(cv *canvas, ticks int) {
+	x, y := w.x-1, w.y-1
+	switch w.typ {
+	case "string":
+		cv.text(x, y, []rune(w.text))
+	case "title":
+		s := []rune(w.text)
+		if n := smclcd.Columns - 4; len(s) > n {
+			s = s[:n]
+		}
+		for i := 0; i < smclcd.Columns; i++ {
+			cv.put(i, 0, cell{c: 0xff})
+		}
+		cv.put(2, 0, cell{c: ' '})
+		cv.text(3, 0, s)
+		cv.put(3+len(s), 0, cell{c: ' '})
+	case "hbar":
+		for n := w.n; n > 0; n -= 5 {
+			if n >= 5 {
+				cv.put(x, y, cell{c: 0xff})
+			} else {
+				var g smclcd.Glyph
+				for i := range g {
+					g[i] = 0x1f &^ (0x1f >> n)
+				}
+				cv.put(x, y, glyphCell(g, ' '))
+			}
+			x++
+		}
+	case "vbar":
+		for n := w.n; n > 0; n -= 8 {
+			if n >= 8 {
+				cv.put(x, y, cell{c: 0xff})
+			} else {
+				var g smclcd.Glyph
+				for i := len(g) - n; i < len(g); i++ {
+					g[i] = 0x1f
+				}
+				cv.put(x, y, glyphCell(g, '_'))
+			}
+			y--
+		}
+	case "icon":
+		cv.put(x, y, icons[w.text])
+	case "scroller":
+		w.drawScroller(cv, ticks)
+	}
+}
+
+func (w *widget) drawScroller(cv *canvas, ticks int) {
+	width, height := w.r-w.x+1, w.b-w.y+1
+	if width <= 0 || height <= 0 {
+		return
+	}
+	var step int
+	switch {
+	case w.n > 0:
+		step = ticks / w.n
+	case w.n < 0:
+		step = ticks * -w.n
+	}
+
+	s := []rune(w.text)
+	switch w.dir {
+	case "v":
+		var lines [][]rune
+		for len(s) > width {
+			lines = append(lines, s[:width])
+			s = s[width:]
+		}
+		lines = append(lines, s)
+		var off int
+		if n := len(lines) - height + 1; n > 1 {
+			off = step % n
+		}
+		for i := 0; i < height && off+i < len(lines); i++ {
+			cv.text(w.x-1, w.y-1+i, lines[off+i])
+		}
+	case "m":
+		if len(s) > width {
+			loop := append(s, ' ')
+			off := step % len(loop)
+			s = append(loop[off:], loop[:off]...)[:width]
+		}
+		cv.text(w.x-1, w.y-1, s)
+	default:
+		if n := len(s) - width + 1; n > 1 {
+			off := step % n
+			s = s[off : off+width]
+		}
+		cv.text(w.x-1, w.y-1, s)
+	}
+}
+
+// run updates the display every tick. Errors are logged once until the
+// next successful update, which redraws the display in full.
+func (s *Server) run() {
+	var failed bool
+	for range time.Tick(tick) {
+		s.mu.Lock()
+		err := s.update()
+		if err != nil {
+			s.frame = nil
+		}
+		s.mu.Unlock()
+		if err != nil && !failed {
+			s.logf("lcdproc: %v", err)
+		}
+		failed = err != nil
+	}
+}
+
+func (s *Server) update() (err error) {
+	s.ticks++
+	s.selectScreen()
+
+	var cv = newCanvas()
+	var nums []*widget
+	var sc = s.cur
+	if sc == nil {
+		cv.text(0, 0, []rune("LCDproc Server"))
+		cv.text(0, 1, []rune(fmt.Sprintf("Cli:%d Scr:%d", len(s.clients), len(s.screens))))
+	} else {
+		for _, w := range sc.widgets {
+			if w.typ == "num" {
+				nums = append(nums, w)
+			} else {
+				w.draw(&cv, s.ticks)
+			}
+		}
+	}
+
+	var f smclcd.Frame
+	if f, err = s.resolve(&cv); err != nil {
+		return
+	}
+	for _, w := range nums {
+		d := ":"
+		if w.n < 10 {
+			d = strconv.Itoa(w.n)
+		}
+		if w.x < 1 || w.x > smclcd.Columns {
+			continue
+		}
+		if s.LCD.RenderBigNumber(&f, w.x-1, d) != nil {
+			f.Line(0)[w.x-1] = d[0]
+		}
+	}
+	if err = s.LCD.DrawFrame(s.frame, &f); err != nil {
+		return
+	}
+	s.frame = &f
+
+	if err = s.updateCursor(sc); err != nil {
+		return
+	}
+	return s.updateBacklight(sc)
+}
+
+// selectScreen chooses the screen to display, rotating between screens
+// of the highest priority.
+func (s *Server) selectScreen() {
+	var best = priorityHidden
+	for _, sc := range s.screens {
+		if sc.priority > best {
+			best = sc.priority
+		}
+	}
+
+	old := s.cur
+	if old != nil && old.timeout == 0 {
+		s.removeScreen(old)
+		old.cl.send("ignore " + old.id)
+		old = nil
+	}
+	s.shown++
+	if s.cur == nil || s.cur.priority != best || s.shown > s.cur.duration {
+		var start = -1
+		for i, sc := range s.screens {
+			if sc == s.cur {
+				start = i
+			}
+		}
+		s.cur = nil
+		for i := 1; i <= len(s.screens); i++ {
+			sc := s.screens[(start+i+len(s.screens))%len(s.screens)]
+			if best > priorityHidden && sc.priority == best {
+				s.cur = sc
+				break
+			}
+		}
+	}
+	if s.cur != old {
+		if old != nil {
+			old.cl.send("ignore " + old.id)
+		}
+		if s.cur != nil {
+			s.cur.cl.send("listen " + s.cur.id)
+		}
+		s.shown = 0
+	}
+	if s.cur != nil && s.cur.timeout > 0 {
+		s.cur.timeout--
+	}
+}
+
+// resolve converts cv to a Frame, allocating glyph slots for custom
+// cells. Slots allocated for the previous frame are released.
+func (s *Server) resolve(cv *canvas) (f smclcd.Frame, err error) {
+	for _, slot := range s.slots {
+		s.LCD.FreeGlyph(slot)
+	}
+	s.slots = s.slots[:0]
+
+	var slots = make(map[smclcd.Glyph]byte)
+	var full bool
+	for y := range cv {
+		line := f.Line(y)
+		for x, c := range cv[y] {
+			line[x] = c.c
+			if !c.custom || full {
+				continue
+			}
+			slot, ok := slots[c.g]
+			if !ok {
+				if slot, err = s.LCD.AllocGlyph(c.g); err != nil {
+					if err != smclcd.ErrNoGlyphSlots {
+						return
+					}
+					err, full = nil, true
+					continue
+				}
+				slots[c.g] = slot
+				s.slots = append(s.slots, slot)
+			}
+			line[x] = slot
+		}
+	}
+	return
+}
+
+func (s *Server) updateCursor(sc *screen) (err error) {
+	var cursor = smclcd.CursorOff
+	var pos int
+	if sc != nil && sc.cursor != smclcd.CursorOff {
+		cursor = sc.cursor
+		pos = (sc.cursorY-1)*smclcd.Columns + sc.cursorX - 1
+	}
+	if cursor != smclcd.CursorOff && (pos != s.pos || !s.init) {
+		if _, err = s.LCD.Seek(int64(pos), io.SeekStart); err != nil {
+			return
+		}
+		s.pos = pos
+	}
+	if cursor != s.cursor || !s.init {
+		if err = s.LCD.SetCursor(cursor); err != nil {
+			return
+		}
+		s.cursor = cursor
+	}
+	return
+}
+
+func (s *Server) updateBacklight(sc *screen) (err error) {
+	mode := "open"
+	if sc != nil {
+		mode = sc.backlight
+		if mode == "open" {
+			mode = sc.cl.backlight
+		}
+	}
+	var light = smclcd.BacklightOn
+	switch mode {
+	case "off":
+		light = smclcd.BacklightOff
+	case "blink":
+		if s.ticks%16 >= 12 {
+			light = smclcd.BacklightOff
+		}
+	case "flash":
+		if s.ticks%4 >= 2 {
+			light = smclcd.BacklightOff
+		}
+	}
+	if light != s.light || !s.init {
+		if err = s.LCD.SetBacklight(light); err != nil {
+			return
+		}
+		s.light = light
+	}
+	s.init = true
+	return
+}
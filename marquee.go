@@ -0,0 +1,170 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"sync"
+	"time"
+)
+
+type MarqueeMode byte
+
+//go:generate stringer -type MarqueeMode -trimprefix=Marquee
+
+const (
+	MarqueeWrap MarqueeMode = iota
+	MarqueeBounce
+)
+
+// Marquee scrolls text that does not fit within a single-line region.
+// Text is advanced by one column every Speed and pauses for Pause at the
+// start (and for MarqueeBounce, the end) of the text. In MarqueeWrap
+// mode, Gap separates the end of the text from its start. Text that fits
+// within the region is displayed without scrolling.
+type Marquee struct {
+	Mode  MarqueeMode
+	Speed time.Duration
+	Pause time.Duration
+	Gap   string
+
+	l      *LCD
+	r      Region
+	mu     sync.Mutex
+	text   string
+	update chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func (l *LCD) NewMarquee(r Region) *Marquee {
+	r.Height = 1
+	return &Marquee{
+		Speed:  300 * time.Millisecond,
+		Pause:  2 * time.Second,
+		Gap:    "   ",
+		l:      l,
+		r:      r,
+		update: make(chan struct{}, 1),
+	}
+}
+
+// SetText replaces the text of the marquee and restarts scrolling. It is
+// safe to call SetText while the marquee is running.
+func (m *Marquee) SetText(text string) {
+	m.mu.Lock()
+	m.text = text
+	m.mu.Unlock()
+
+	select {
+	case m.update <- struct{}{}:
+	default:
+	}
+}
+
+// Start begins scrolling in the background. Errors updating the display
+// are ignored. Start has no effect if the marquee is already running.
+func (m *Marquee) Start() error {
+	if m.done != nil {
+		return nil
+	}
+	if err := m.r.check(); err != nil {
+		return err
+	}
+	m.done = make(chan struct{})
+	select {
+	case m.update <- struct{}{}: // draw the initial text
+	default:
+	}
+	m.wg.Add(1)
+	go m.run()
+	return nil
+}
+
+// Stop stops scrolling and waits for the marquee to finish. Stop has no
+// effect if the marquee is not running.
+func (m *Marquee) Stop() {
+	if m.done == nil {
+		return
+	}
+	close(m.done)
+	m.wg.Wait()
+	m.done = nil
+}
+
+func (m *Marquee) run() {
+	defer m.wg.Done()
+
+	var text []rune
+	var off, dir int
+	var delay = time.Hour
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-m.update:
+			m.mu.Lock()
+			text = []rune(m.text)
+			m.mu.Unlock()
+			off, dir = 0, 1
+		case <-time.After(delay):
+		}
+
+		if len(text) <= m.r.Width {
+			m.l.SetRegion(m.r, string(text))
+			delay = time.Hour
+			continue
+		}
+
+		var s []rune
+		switch m.Mode {
+		case MarqueeBounce:
+			s = text[off : off+m.r.Width]
+			if end := len(text) - m.r.Width; off == 0 || off == end {
+				delay = m.Pause
+			} else {
+				delay = m.Speed
+			}
+			if off+dir < 0 || off+dir > len(text)-m.r.Width {
+				dir = -dir
+			}
+		default:
+			loop := append(text[:len(text):len(text)], []rune(m.Gap)...)
+			s = make([]rune, m.r.Width)
+			for i := range s {
+				s[i] = loop[(off+i)%len(loop)]
+			}
+			if off == 0 {
+				delay = m.Pause
+			} else {
+				delay = m.Speed
+			}
+			dir = 1
+			if off+dir == len(loop) {
+				off = -dir
+			}
+		}
+		m.l.SetRegion(m.r, string(s))
+		off += dir
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMarqueeStopBeforeStart(t *testing.T) {
+	m := New(newFakeDevice()).NewMarquee(Region{Width: 4})
+	m.Stop()
+}
+
+func TestMarqueeStartTwice(t *testing.T) {
+	m := New(newFakeDevice()).NewMarquee(Region{Width: 4})
+	for i := 0; i < 2; i++ {
+		if err := m.Start(); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Stop waits for every goroutine started by Start.
+		done := make(chan struct{})
+		go func() {
+			m.Stop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Stop did not return")
+		}
+	}
+}
+
+func TestMarqueeRunes(t *testing.T) {
+	tests := []struct {
+		mode MarqueeMode
+		want []string
+	}{
+		{MarqueeBounce, []string{"\xe1bcd", "bcde", "cdef", "bcde", "\xe1bcd"}},
+		{MarqueeWrap, []string{"\xe1bcd", "bcde", "cdef", "def ", "ef \xe1", "f \xe1b", " \xe1bc", "\xe1bcd"}},
+	}
+	for _, tt := range tests {
+		d := newFakeDevice()
+		m := New(d).NewMarquee(Region{Width: 4})
+		m.Mode = tt.mode
+		m.Speed = 10 * time.Millisecond
+		m.Pause = 10 * time.Millisecond
+		m.Gap = " "
+		m.SetText("äbcdef")
+		if err := m.Start(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Duration(len(tt.want)*10+50) * time.Millisecond)
+		m.Stop()
+
+		want := []byte(nil)
+		for _, s := range tt.want {
+			want = append(want, s...)
+		}
+		if !bytes.HasPrefix(d.Written(), want) {
+			t.Errorf("%v: written %q, want prefix %q", tt.mode, d.Written(), want)
+		}
+	}
+}
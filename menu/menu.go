@@ -0,0 +1,235 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package menu implements hierarchical menus navigated using the keys on
+// the front panel.
+package menu
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// Item is an entry in a menu. If Items is non-empty, selecting the item
+// opens a submenu; otherwise Action is called. Func, if set, is called
+// each time the item is drawn to provide a dynamic label.
+type Item struct {
+	Label  string
+	Func   func() string
+	Action func(m *Menu) error
+	Items  []*Item
+}
+
+func (item *Item) label() string {
+	if item.Func != nil {
+		return item.Func()
+	}
+	return item.Label
+}
+
+// ErrCanceled is returned by actions canceled by the user.
+var ErrCanceled = errors.New("canceled")
+
+type level struct {
+	items    []*Item
+	sel, top int
+}
+
+// Menu displays Items and dispatches gestures read from Input. The menu
+// is opened from the idle screen by any key and returns to it after
+// Timeout has elapsed without input or when Cancel is pressed at the top
+// level. The idle screen and dynamic labels are redrawn every Refresh.
+// Marker is shown before the selected item; like labels, it is mapped
+// to the character ROM as by SetLine.
+type Menu struct {
+	Items   []*Item
+	Idle    func(l *smclcd.LCD) error
+	Timeout time.Duration
+	Refresh time.Duration
+	Marker  rune
+
+	LCD   *smclcd.LCD
+	Input *smclcd.GestureReader
+
+	stack []level
+}
+
+func New(l *smclcd.LCD, input *smclcd.GestureReader, items ...*Item) *Menu {
+	return &Menu{
+		Items:   items,
+		Timeout: 30 * time.Second,
+		Refresh: time.Second,
+		Marker:  '>',
+		LCD:     l,
+		Input:   input,
+	}
+}
+
+// Run displays the menu until an error occurs reading input.
+func (m *Menu) Run() (err error) {
+	for {
+		if err = m.idle(); err != nil {
+			return
+		}
+		m.stack = []level{{items: m.Items}}
+		if err = m.loop(); err != nil {
+			return
+		}
+	}
+}
+
+func (m *Menu) idle() (err error) {
+	if err = m.LCD.SetCursor(smclcd.CursorOff); err != nil {
+		return
+	}
+	if err = m.LCD.Clear(); err != nil {
+		return
+	}
+	for {
+		if m.Idle != nil {
+			if err = m.Idle(m.LCD); err != nil {
+				return
+			}
+		}
+		if _, err = m.next(time.Time{}); err != smclcd.ErrTimeout {
+			return
+		}
+	}
+}
+
+func (m *Menu) loop() (err error) {
+	var active = time.Now()
+	for len(m.stack) > 0 {
+		if err = m.draw(); err != nil {
+			return
+		}
+
+		var e smclcd.GestureEvent
+		if e, err = m.next(active); err == smclcd.ErrTimeout {
+			if m.Timeout > 0 && time.Since(active) >= m.Timeout {
+				m.stack = nil
+			}
+			continue
+		} else if err != nil {
+			return
+		}
+		active = time.Now()
+
+		if e.Gesture != smclcd.GestureClick && e.Gesture != smclcd.GestureRepeat {
+			continue
+		}
+		if err = m.handle(e.Code); err != nil {
+			return
+		}
+	}
+	return nil
+}
+
+func (m *Menu) next(active time.Time) (smclcd.GestureEvent, error) {
+	var d time.Duration
+	if m.Timeout > 0 && !active.IsZero() {
+		d = m.Timeout - time.Since(active)
+	}
+	if m.Refresh > 0 && (d == 0 || m.Refresh < d) {
+		d = m.Refresh
+	}
+	if d == 0 {
+		return m.Input.Next()
+	}
+	return m.Input.NextTimeout(d)
+}
+
+func (m *Menu) handle(code smclcd.KeyCode) error {
+	lv := &m.stack[len(m.stack)-1]
+	switch code {
+	case smclcd.KeyUp:
+		if lv.sel > 0 {
+			lv.sel--
+		}
+	case smclcd.KeyDown:
+		if lv.sel < len(lv.items)-1 {
+			lv.sel++
+		}
+	case smclcd.KeyEnter, smclcd.KeyRight:
+		if len(lv.items) == 0 {
+			break
+		}
+		item := lv.items[lv.sel]
+		if len(item.Items) > 0 {
+			m.stack = append(m.stack, level{items: item.Items})
+		} else if item.Action != nil {
+			return m.run(item)
+		}
+	case smclcd.KeyCancel, smclcd.KeyLeft:
+		m.stack = m.stack[:len(m.stack)-1]
+	}
+	if lv.sel < lv.top {
+		lv.top = lv.sel
+	} else if lv.sel >= lv.top+smclcd.Lines {
+		lv.top = lv.sel - smclcd.Lines + 1
+	}
+	return nil
+}
+
+func (m *Menu) run(item *Item) (err error) {
+	if err = m.LCD.SetCursor(smclcd.CursorOff); err != nil {
+		return
+	}
+	var actionErr error
+	if actionErr = item.Action(m); actionErr == nil || actionErr == ErrCanceled {
+		return m.LCD.SetCursor(smclcd.CursorOff)
+	}
+	if err = m.LCD.SetLine(0, "Error:", smclcd.AlignLeft); err != nil {
+		return
+	}
+	if err = m.LCD.SetLine(1, actionErr.Error(), smclcd.AlignLeft); err != nil {
+		return
+	}
+	if _, err = m.Input.NextTimeout(5 * time.Second); err == smclcd.ErrTimeout {
+		err = nil
+	}
+	return
+}
+
+func (m *Menu) draw() (err error) {
+	if len(m.stack) == 0 {
+		return
+	}
+	lv := m.stack[len(m.stack)-1]
+	for y := 0; y < smclcd.Lines; y++ {
+		var s string
+		if i := lv.top + y; i < len(lv.items) {
+			marker := " "
+			if i == lv.sel {
+				marker = string(m.Marker)
+			}
+			s = marker + lv.items[i].label()
+		}
+		if err = m.LCD.SetLine(y, s, smclcd.AlignLeft); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package menu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+// click presses and releases code.
+func click(d *fakehid.Device, code smclcd.KeyCode) {
+	d.Key(byte(code), byte(smclcd.KeyPress))
+	d.Key(byte(code), byte(smclcd.KeyRelease))
+}
+
+// waitText waits for the display to show want.
+func waitText(t *testing.T, d *fakehid.Device, want string) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+		if d.Text() == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("display = %q, want %q", d.Text(), want)
+}
+
+func TestNavigation(t *testing.T) {
+	d := fakehid.New(smclcd.ErrTimeout)
+	defer d.Close()
+	l := smclcd.New(d)
+	input := smclcd.NewGestureReader(l)
+	defer input.Close()
+
+	rebooted := make(chan bool, 1)
+	m := New(l, input,
+		&Item{Label: "Network", Items: []*Item{
+			{Label: "IP"},
+			{Label: "Mask"},
+		}},
+		&Item{Label: "Reboot", Action: func(m *Menu) error {
+			rebooted <- true
+			return nil
+		}},
+		&Item{Label: "Info"},
+	)
+	m.Timeout = 0
+	m.Refresh = 0
+	m.Marker = '→'
+	m.stack = []level{{items: m.Items}}
+	errc := make(chan error, 1)
+	go func() { errc <- m.loop() }()
+
+	waitText(t, d, "\x7eNetwork         Reboot         ")
+	steps := []struct {
+		code smclcd.KeyCode
+		want string
+	}{
+		{smclcd.KeyDown, " Network        \x7eReboot         "},
+		{smclcd.KeyDown, " Reboot         \x7eInfo           "},
+		{smclcd.KeyDown, " Reboot         \x7eInfo           "},
+		{smclcd.KeyUp, "\x7eReboot          Info           "},
+		{smclcd.KeyUp, "\x7eNetwork         Reboot         "},
+		{smclcd.KeyEnter, "\x7eIP              Mask           "},
+		{smclcd.KeyDown, " IP             \x7eMask           "},
+		{smclcd.KeyCancel, "\x7eNetwork         Reboot         "},
+		{smclcd.KeyRight, "\x7eIP              Mask           "},
+		{smclcd.KeyLeft, "\x7eNetwork         Reboot         "},
+		{smclcd.KeyDown, " Network        \x7eReboot         "},
+	}
+	for _, step := range steps {
+		click(d, step.code)
+		waitText(t, d, step.want)
+	}
+
+	click(d, smclcd.KeyEnter)
+	select {
+	case <-rebooted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("action not called")
+	}
+
+	// Cancel at the top level closes the menu.
+	click(d, smclcd.KeyCancel)
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("loop() = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("menu not closed")
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// A widget edits a value in place. The first line of the display shows
+// the label and the second line shows the value being edited. Up and
+// Down change the value at the cursor, Left and Right move the cursor,
+// Enter accepts the value, and Cancel discards it. Edit returns
+// ErrCanceled if the value was discarded or Timeout elapsed.
+type widget interface {
+	value() (s string, col int)
+	key(code smclcd.KeyCode)
+}
+
+// A header is a widget that shows part of its value on the first line
+// in place of the label, for values too wide for the second line.
+type header interface {
+	header() string
+}
+
+func (m *Menu) edit(label string, w widget) (err error) {
+	if err = m.LCD.SetCursor(smclcd.CursorUnderline); err != nil {
+		return
+	}
+	var top string
+	for i := 0; ; i++ {
+		s := label
+		if h, ok := w.(header); ok {
+			s = h.header()
+		}
+		if s != top || i == 0 {
+			if err = m.LCD.SetLine(0, s, smclcd.AlignLeft); err != nil {
+				return
+			}
+			top = s
+		}
+
+		s, col := w.value()
+		if err = m.LCD.SetLine(1, s, smclcd.AlignLeft); err != nil {
+			return
+		}
+		if err = m.LCD.MoveCursor(1, col); err != nil {
+			return
+		}
+
+		var e smclcd.GestureEvent
+		if m.Timeout > 0 {
+			e, err = m.Input.NextTimeout(m.Timeout)
+		} else {
+			e, err = m.Input.Next()
+		}
+		if err == smclcd.ErrTimeout {
+			return ErrCanceled
+		} else if err != nil {
+			return
+		}
+
+		if e.Gesture != smclcd.GestureClick && e.Gesture != smclcd.GestureRepeat {
+			continue
+		}
+		switch e.Code {
+		case smclcd.KeyEnter:
+			return
+		case smclcd.KeyCancel:
+			return ErrCanceled
+		default:
+			w.key(e.Code)
+		}
+	}
+}
+
+// Spinner edits a number between Min and Max. Up and Down change Value
+// by Step; Left and Right change it by ten times Step. Value is displayed
+// with Precision decimal places. If Min and Max are both zero, Value is
+// unbounded; Edit fails if Min is greater than Max.
+type Spinner struct {
+	Label          string
+	Value          float64
+	Min, Max, Step float64
+	Precision      int
+}
+
+type spinner struct {
+	*Spinner
+	v float64
+}
+
+func (s *Spinner) Edit(m *Menu) (err error) {
+	if s.Min > s.Max {
+		return errors.New("menu: spinner minimum greater than maximum")
+	}
+	w := &spinner{s, s.Value}
+	if err = m.edit(s.Label, w); err == nil {
+		s.Value = w.v
+	}
+	return
+}
+
+func (w *spinner) value() (string, int) {
+	s := strconv.FormatFloat(w.v, 'f', w.Precision, 64)
+	return s, lastCol(s)
+}
+
+// lastCol returns the column of the last character of s, clamped to the
+// display.
+func lastCol(s string) int {
+	if len(s) == 0 {
+		return 0
+	}
+	if len(s) > smclcd.Columns {
+		return smclcd.Columns - 1
+	}
+	return len(s) - 1
+}
+
+func (w *spinner) key(code smclcd.KeyCode) {
+	step := w.Step
+	if step == 0 {
+		step = 1
+	}
+	switch code {
+	case smclcd.KeyUp:
+		w.v += step
+	case smclcd.KeyDown:
+		w.v -= step
+	case smclcd.KeyRight:
+		w.v += 10 * step
+	case smclcd.KeyLeft:
+		w.v -= 10 * step
+	}
+	scale := math.Pow10(w.Precision)
+	w.v = math.Round(w.v*scale) / scale
+	if w.Min != 0 || w.Max != 0 {
+		w.v = math.Max(w.Min, math.Min(w.Max, w.v))
+	}
+}
+
+// Toggle edits a boolean. Any arrow key toggles Value, which is
+// displayed using Labels; the default labels are "No" and "Yes".
+type Toggle struct {
+	Label  string
+	Value  bool
+	Labels [2]string
+}
+
+type toggle struct {
+	*Toggle
+	v bool
+}
+
+func (t *Toggle) Edit(m *Menu) (err error) {
+	w := &toggle{t, t.Value}
+	if err = m.edit(t.Label, w); err == nil {
+		t.Value = w.v
+	}
+	return
+}
+
+func (w *toggle) value() (string, int) {
+	labels := w.Labels
+	if labels == [2]string{} {
+		labels = [2]string{"No", "Yes"}
+	}
+	if w.v {
+		return labels[1], 0
+	}
+	return labels[0], 0
+}
+
+func (w *toggle) key(code smclcd.KeyCode) {
+	w.v = !w.v
+}
+
+// IPAddr edits an IPv4 address one octet at a time. Left and Right
+// select an octet and Up and Down change it.
+type IPAddr struct {
+	Label string
+	IP    net.IP
+}
+
+type ipAddr struct {
+	octets [net.IPv4len]byte
+	i      int
+}
+
+func (a *IPAddr) Edit(m *Menu) (err error) {
+	w := new(ipAddr)
+	if ip := a.IP.To4(); ip != nil {
+		copy(w.octets[:], ip)
+	}
+	if err = m.edit(a.Label, w); err == nil {
+		a.IP = net.IP(w.octets[:]).To16()
+	}
+	return
+}
+
+func (w *ipAddr) value() (string, int) {
+	o := w.octets
+	return fmt.Sprintf("%03d.%03d.%03d.%03d", o[0], o[1], o[2], o[3]), w.i*4 + 2
+}
+
+func (w *ipAddr) key(code smclcd.KeyCode) {
+	switch code {
+	case smclcd.KeyUp:
+		w.octets[w.i]++
+	case smclcd.KeyDown:
+		w.octets[w.i]--
+	case smclcd.KeyRight:
+		w.i = (w.i + 1) % len(w.octets)
+	case smclcd.KeyLeft:
+		w.i = (w.i + len(w.octets) - 1) % len(w.octets)
+	}
+}
+
+// Netmask edits an IPv4 netmask by prefix length. Up and Right lengthen
+// the prefix and Down and Left shorten it. The prefix length is shown on
+// the second line and the netmask in place of the label.
+type Netmask struct {
+	Label string
+	Mask  net.IPMask
+}
+
+type netmask struct {
+	ones int
+}
+
+func (n *Netmask) Edit(m *Menu) (err error) {
+	w := &netmask{24}
+	if ones, bits := n.Mask.Size(); bits == 8*net.IPv4len {
+		w.ones = ones
+	}
+	if err = m.edit(n.Label, w); err == nil {
+		n.Mask = net.CIDRMask(w.ones, 8*net.IPv4len)
+	}
+	return
+}
+
+func (w *netmask) header() string {
+	return net.IP(net.CIDRMask(w.ones, 8*net.IPv4len)).String()
+}
+
+func (w *netmask) value() (string, int) {
+	s := fmt.Sprintf("/%d", w.ones)
+	return s, lastCol(s)
+}
+
+func (w *netmask) key(code smclcd.KeyCode) {
+	switch code {
+	case smclcd.KeyUp, smclcd.KeyRight:
+		if w.ones < 8*net.IPv4len {
+			w.ones++
+		}
+	case smclcd.KeyDown, smclcd.KeyLeft:
+		if w.ones > 0 {
+			w.ones--
+		}
+	}
+}
+
+// DefaultCharset is the set of characters used by TextEntry if Charset
+// is empty.
+const DefaultCharset = " ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_.:/@"
+
+// TextEntry edits a string one character at a time. Up and Down cycle
+// the character at the cursor through Charset, and Left and Right move
+// the cursor. Value is clipped to MaxLen characters, or the width of the
+// display if MaxLen is not set. Trailing spaces are removed from the
+// accepted Value.
+type TextEntry struct {
+	Label   string
+	Value   string
+	Charset string
+	MaxLen  int
+}
+
+type textEntry struct {
+	*TextEntry
+	charset []rune
+	r       []rune
+	i       int
+}
+
+func newTextEntry(t *TextEntry) *textEntry {
+	w := &textEntry{TextEntry: t, charset: []rune(t.Charset), r: []rune(t.Value)}
+	if len(w.charset) == 0 {
+		w.charset = []rune(DefaultCharset)
+	}
+	if n := w.maxLen(); len(w.r) > n {
+		w.r = w.r[:n]
+	}
+	if len(w.r) == 0 {
+		w.r = []rune(" ")
+	}
+	return w
+}
+
+func (t *TextEntry) Edit(m *Menu) (err error) {
+	w := newTextEntry(t)
+	if err = m.edit(t.Label, w); err == nil {
+		t.Value = strings.TrimRight(string(w.r), " ")
+	}
+	return
+}
+
+func (w *textEntry) maxLen() int {
+	if w.MaxLen <= 0 || w.MaxLen > smclcd.Columns {
+		return smclcd.Columns
+	}
+	return w.MaxLen
+}
+
+func (w *textEntry) value() (string, int) {
+	return string(w.r), w.i
+}
+
+func (w *textEntry) index(c rune) int {
+	for i, r := range w.charset {
+		if r == c {
+			return i
+		}
+	}
+	return -1
+}
+
+func (w *textEntry) key(code smclcd.KeyCode) {
+	charset := w.charset
+	switch code {
+	case smclcd.KeyUp, smclcd.KeyDown:
+		// Characters not in charset start from either end.
+		i := w.index(w.r[w.i])
+		switch {
+		case i < 0 && code == smclcd.KeyUp:
+			i = 0
+		case i < 0:
+			i = len(charset) - 1
+		case code == smclcd.KeyUp:
+			i = (i + 1) % len(charset)
+		default:
+			i = (i + len(charset) - 1) % len(charset)
+		}
+		w.r[w.i] = charset[i]
+	case smclcd.KeyRight:
+		if w.i+1 < w.maxLen() {
+			if w.i++; w.i == len(w.r) {
+				w.r = append(w.r, charset[0])
+			}
+		}
+	case smclcd.KeyLeft:
+		if w.i > 0 {
+			w.i--
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package menu
+
+import (
+	"testing"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+func TestNetmask(t *testing.T) {
+	tests := []struct {
+		ones   int
+		header string
+		value  string
+	}{
+		{0, "0.0.0.0", "/0"},
+		{24, "255.255.255.0", "/24"},
+		{25, "255.255.255.128", "/25"},
+		{32, "255.255.255.255", "/32"},
+	}
+	for _, tt := range tests {
+		w := &netmask{tt.ones}
+		if s := w.header(); s != tt.header {
+			t.Errorf("header() = %q, want %q", s, tt.header)
+		}
+		s, col := w.value()
+		if s != tt.value || col != len(s)-1 {
+			t.Errorf("value() = %q, %d, want %q, %d", s, col, tt.value, len(tt.value)-1)
+		}
+	}
+}
+
+func TestSpinnerColumn(t *testing.T) {
+	w := &spinner{&Spinner{Precision: 6}, -123456789.5}
+	s, col := w.value()
+	if len(s) <= smclcd.Columns {
+		t.Fatalf("value() = %q, want more than %d characters", s, smclcd.Columns)
+	}
+	if col != smclcd.Columns-1 {
+		t.Errorf("value() column = %d, want %d", col, smclcd.Columns-1)
+	}
+}
+
+func TestTextEntryCharset(t *testing.T) {
+	tests := []struct {
+		value string
+		code  smclcd.KeyCode
+		want  string
+	}{
+		{"a", smclcd.KeyUp, "b"},
+		{"a", smclcd.KeyDown, "c"},
+		{"c", smclcd.KeyUp, "a"},
+		{"x", smclcd.KeyUp, "a"},
+		{"x", smclcd.KeyDown, "c"},
+	}
+	for _, tt := range tests {
+		w := newTextEntry(&TextEntry{Value: tt.value, Charset: "abc"})
+		w.key(tt.code)
+		if s, _ := w.value(); s != tt.want {
+			t.Errorf("%q after %v = %q, want %q", tt.value, tt.code, s, tt.want)
+		}
+	}
+}
+
+func TestTextEntry(t *testing.T) {
+	tests := []struct {
+		value   string
+		charset string
+		maxLen  int
+		keys    []smclcd.KeyCode
+		want    string
+		col     int
+	}{
+		{"", "", 0, nil, " ", 0},
+		{"abcdef", "", 3, nil, "abc", 0},
+		{"abcdefghijklmnopqrstuvwxyz", "", 0, nil, "abcdefghijklmnop", 0},
+		{"café", "", 3, nil, "caf", 0},
+		{"ab", "", 3, []smclcd.KeyCode{smclcd.KeyRight, smclcd.KeyRight, smclcd.KeyRight}, "ab ", 2},
+		{"ça", "çaé", 0, []smclcd.KeyCode{smclcd.KeyUp}, "aa", 0},
+		{"çé", "çaé", 0, []smclcd.KeyCode{smclcd.KeyRight, smclcd.KeyUp}, "çç", 1},
+		{"é", "xé", 0, []smclcd.KeyCode{smclcd.KeyRight, smclcd.KeyDown}, "éé", 1},
+		{"日本", "", 0, []smclcd.KeyCode{smclcd.KeyRight, smclcd.KeyRight, smclcd.KeyLeft}, "日本 ", 1},
+	}
+	for _, tt := range tests {
+		w := newTextEntry(&TextEntry{Value: tt.value, Charset: tt.charset, MaxLen: tt.maxLen})
+		for _, code := range tt.keys {
+			w.key(code)
+		}
+		if s, col := w.value(); s != tt.want || col != tt.col {
+			t.Errorf("%q after %v = %q, %d, want %q, %d", tt.value, tt.keys, s, col, tt.want, tt.col)
+		}
+	}
+}
+
+func TestSpinnerBounds(t *testing.T) {
+	tests := []struct {
+		min, max float64
+		v        float64
+		code     smclcd.KeyCode
+		want     float64
+	}{
+		{0, 0, 5, smclcd.KeyRight, 15},
+		{0, 0, -5, smclcd.KeyLeft, -15},
+		{0, 10, 5, smclcd.KeyRight, 10},
+		{0, 10, 5, smclcd.KeyLeft, 0},
+		{-10, -5, -5, smclcd.KeyUp, -5},
+		{3, 3, 0, smclcd.KeyUp, 3},
+	}
+	for _, tt := range tests {
+		w := &spinner{&Spinner{Min: tt.min, Max: tt.max}, tt.v}
+		w.key(tt.code)
+		if w.v != tt.want {
+			t.Errorf("%v in [%v, %v] after %v = %v, want %v", tt.v, tt.min, tt.max, tt.code, w.v, tt.want)
+		}
+	}
+
+	if err := (&Spinner{Min: 1, Max: 0}).Edit(nil); err == nil {
+		t.Error("Edit with Min > Max succeeded")
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package mqtt implements a minimal MQTT 3.1.1 client supporting
+// publishing and subscribing at QoS 0, retained messages, and wills.
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Packet types.
+const (
+	pConnect    = 1
+	pConnack    = 2
+	pPublish    = 3
+	pPuback     = 4
+	pSubscribe  = 8
+	pSuback     = 9
+	pPingreq    = 12
+	pPingresp   = 13
+	pDisconnect = 14
+)
+
+// Connect flags.
+const (
+	fCleanSession = 0x02
+	fWill         = 0x04
+	fWillRetain   = 0x20
+	fPassword     = 0x40
+	fUsername     = 0x80
+)
+
+const dialTimeout = 10 * time.Second
+
+// maxPacketLen is the largest remaining length accepted from the broker.
+const maxPacketLen = 1 << 20
+
+// Message is an application message.
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Options configures a connection. Handler is called from the
+// connection's receive goroutine for each message received on a
+// subscribed topic.
+type Options struct {
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	Will      *Message
+	Handler   func(m *Message)
+}
+
+// Client is a connection to a broker. It is safe for concurrent use by
+// multiple goroutines.
+type Client struct {
+	c    net.Conn
+	opts Options
+
+	r   *bufio.Reader
+	wmu sync.Mutex
+	w   *bufio.Writer
+
+	mu     sync.Mutex
+	id     uint16
+	subs   map[uint16]chan error
+	err    error
+	done   chan struct{}
+	closed bool
+}
+
+// Dial connects to the broker listening on addr.
+func Dial(addr string, opts *Options) (*Client, error) {
+	c, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	cl := &Client{
+		c:    c,
+		opts: *opts,
+		r:    bufio.NewReader(c),
+		w:    bufio.NewWriter(c),
+		subs: make(map[uint16]chan error),
+		done: make(chan struct{}),
+	}
+	if err = cl.connect(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	go cl.recv()
+	if cl.opts.KeepAlive > 0 {
+		go cl.ping()
+	}
+	return cl, nil
+}
+
+func (cl *Client) connect() error {
+	var flags byte = fCleanSession
+	var payload []byte
+	payload = appendString(payload, cl.opts.ClientID)
+	if m := cl.opts.Will; m != nil {
+		flags |= fWill
+		if m.Retain {
+			flags |= fWillRetain
+		}
+		payload = appendString(payload, m.Topic)
+		payload = appendBytes(payload, m.Payload)
+	}
+	if cl.opts.Username != "" {
+		flags |= fUsername
+		payload = appendString(payload, cl.opts.Username)
+	}
+	if cl.opts.Password != "" {
+		flags |= fPassword
+		payload = appendString(payload, cl.opts.Password)
+	}
+
+	var b []byte
+	b = appendString(b, "MQTT")
+	b = append(b, 4, flags) // protocol level 3.1.1
+	b = appendUint16(b, uint16(cl.opts.KeepAlive/time.Second))
+	b = append(b, payload...)
+
+	cl.c.SetDeadline(time.Now().Add(dialTimeout))
+	defer cl.c.SetDeadline(time.Time{})
+	if err := cl.send(pConnect<<4, b); err != nil {
+		return err
+	}
+	typ, body, err := readPacket(cl.r)
+	if err != nil {
+		return err
+	}
+	if typ>>4 != pConnack || len(body) != 2 {
+		return errors.New("mqtt: expected CONNACK")
+	}
+	if body[1] != 0 {
+		return fmt.Errorf("mqtt: connection refused: code %d", body[1])
+	}
+	return nil
+}
+
+func (cl *Client) send(header byte, body []byte) error {
+	cl.wmu.Lock()
+	defer cl.wmu.Unlock()
+
+	cl.w.WriteByte(header)
+	n := len(body)
+	for {
+		c := byte(n % 128)
+		if n /= 128; n > 0 {
+			c |= 0x80
+		}
+		cl.w.WriteByte(c)
+		if n == 0 {
+			break
+		}
+	}
+	cl.w.Write(body)
+	return cl.w.Flush()
+}
+
+func readPacket(r *bufio.Reader) (typ byte, body []byte, err error) {
+	if typ, err = r.ReadByte(); err != nil {
+		return
+	}
+	var n, shift int
+	for {
+		var c byte
+		if c, err = r.ReadByte(); err != nil {
+			return
+		}
+		n |= int(c&0x7f) << shift
+		if c&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			err = errors.New("mqtt: malformed remaining length")
+			return
+		}
+	}
+	if n > maxPacketLen {
+		err = fmt.Errorf("mqtt: packet too large: %d bytes", n)
+		return
+	}
+	body = make([]byte, n)
+	_, err = io.ReadFull(r, body)
+	return
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendString(b []byte, s string) []byte {
+	return appendBytes(b, []byte(s))
+}
+
+func appendBytes(b, p []byte) []byte {
+	b = appendUint16(b, uint16(len(p)))
+	return append(b, p...)
+}
+
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("mqtt: malformed string")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("mqtt: malformed string")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
+
+func (cl *Client) recv() {
+	var err error
+	for {
+		if cl.opts.KeepAlive > 0 {
+			cl.c.SetReadDeadline(time.Now().Add(cl.opts.KeepAlive * 3 / 2))
+		}
+		var typ byte
+		var body []byte
+		if typ, body, err = readPacket(cl.r); err != nil {
+			break
+		}
+		switch typ >> 4 {
+		case pPublish:
+			err = cl.handlePublish(typ, body)
+		case pSuback:
+			if len(body) < 3 {
+				err = errors.New("mqtt: malformed SUBACK")
+				break
+			}
+			id := binary.BigEndian.Uint16(body)
+			var ackErr error
+			for _, rc := range body[2:] {
+				if rc == 0x80 {
+					ackErr = errors.New("mqtt: subscription refused")
+				}
+			}
+			cl.mu.Lock()
+			if c, ok := cl.subs[id]; ok {
+				c <- ackErr
+				delete(cl.subs, id)
+			}
+			cl.mu.Unlock()
+		}
+		if err != nil {
+			break
+		}
+	}
+	cl.shutdown(err)
+}
+
+func (cl *Client) handlePublish(typ byte, body []byte) error {
+	topic, rest, err := readString(body)
+	if err != nil {
+		return err
+	}
+	if qos := typ >> 1 & 3; qos > 0 {
+		if len(rest) < 2 {
+			return errors.New("mqtt: malformed PUBLISH")
+		}
+		if qos == 1 {
+			if err = cl.send(pPuback<<4, rest[:2]); err != nil {
+				return err
+			}
+		}
+		rest = rest[2:]
+	}
+	if cl.opts.Handler != nil {
+		cl.opts.Handler(&Message{Topic: topic, Payload: rest, Retain: typ&1 != 0})
+	}
+	return nil
+}
+
+func (cl *Client) ping() {
+	ticker := time.NewTicker(cl.opts.KeepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cl.done:
+			return
+		case <-ticker.C:
+			if cl.send(pPingreq<<4, nil) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (cl *Client) shutdown(err error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.closed {
+		err = nil
+	} else if err == nil || err == io.EOF {
+		err = errors.New("mqtt: connection closed by broker")
+	}
+	cl.err = err
+	for id, c := range cl.subs {
+		c <- errors.New("mqtt: connection closed")
+		delete(cl.subs, id)
+	}
+	cl.c.Close()
+	close(cl.done)
+}
+
+// Publish sends m at QoS 0.
+func (cl *Client) Publish(m *Message) error {
+	var header byte = pPublish << 4
+	if m.Retain {
+		header |= 1
+	}
+	b := appendString(nil, m.Topic)
+	return cl.send(header, append(b, m.Payload...))
+}
+
+// Subscribe subscribes to topic filters at QoS 0 and waits for the
+// broker to acknowledge the subscription.
+func (cl *Client) Subscribe(filters ...string) error {
+	c := make(chan error, 1)
+	cl.mu.Lock()
+	if cl.err != nil || cl.closed {
+		cl.mu.Unlock()
+		return errors.New("mqtt: connection closed")
+	}
+	cl.id++
+	if cl.id == 0 {
+		cl.id++
+	}
+	id := cl.id
+	cl.subs[id] = c
+	cl.mu.Unlock()
+
+	b := appendUint16(nil, id)
+	for _, f := range filters {
+		b = appendString(b, f)
+		b = append(b, 0) // QoS 0
+	}
+	if err := cl.send(pSubscribe<<4|2, b); err != nil {
+		return err
+	}
+	return <-c
+}
+
+// Done returns a channel that is closed when the connection is closed.
+func (cl *Client) Done() <-chan struct{} {
+	return cl.done
+}
+
+// Err returns the error that caused the connection to close, or nil if
+// the connection was closed by Close.
+func (cl *Client) Err() error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.err
+}
+
+// Close disconnects from the broker. The will message is not published.
+func (cl *Client) Close() error {
+	cl.mu.Lock()
+	cl.closed = true
+	cl.mu.Unlock()
+
+	err := cl.send(pDisconnect<<4, nil)
+	cl.c.Close()
+	<-cl.done
+	return err
+}
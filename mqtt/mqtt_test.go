@@ -0,0 +1,220 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// encode returns a packet as written by Client.send.
+func encode(header byte, body []byte) []byte {
+	var buf bytes.Buffer
+	cl := &Client{w: bufio.NewWriter(&buf)}
+	cl.send(header, body)
+	return buf.Bytes()
+}
+
+func TestRemainingLength(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xff, 0x7f}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+		{maxPacketLen, []byte{0x80, 0x80, 0x40}},
+	}
+	for _, tt := range tests {
+		body := bytes.Repeat([]byte{'x'}, tt.n)
+		b := encode(pPublish<<4, body)
+		if got := b[1 : 1+len(tt.want)]; !bytes.Equal(got, tt.want) {
+			t.Errorf("length %d encoded as % x, want % x", tt.n, got, tt.want)
+		}
+		typ, got, err := readPacket(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil || typ != pPublish<<4 || !bytes.Equal(got, body) {
+			t.Errorf("length %d: readPacket() = %#x, %d bytes, %v", tt.n, typ, len(got), err)
+		}
+	}
+}
+
+func TestReadPacketMalformed(t *testing.T) {
+	tests := [][]byte{
+		{},
+		{pPublish << 4},
+		{pPublish << 4, 0x80, 0x80, 0x80, 0x80, 0x01},
+		{pPublish << 4, 0x81, 0x80, 0x40},
+		{pPublish << 4, 0x80, 0x80, 0x80, 0x01},
+		{pPublish << 4, 0x03, 'a', 'b'},
+	}
+	for _, b := range tests {
+		if _, _, err := readPacket(bufio.NewReader(bytes.NewReader(b))); err == nil {
+			t.Errorf("readPacket(% x) succeeded", b)
+		}
+	}
+}
+
+func TestReadString(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		s    string
+		rest []byte
+		ok   bool
+	}{
+		{[]byte{0, 3, 'a', 'b', 'c', 'd'}, "abc", []byte{'d'}, true},
+		{[]byte{0, 0}, "", []byte{}, true},
+		{[]byte{0}, "", nil, false},
+		{[]byte{0, 3, 'a'}, "", nil, false},
+	}
+	for _, tt := range tests {
+		s, rest, err := readString(tt.b)
+		if (err == nil) != tt.ok || s != tt.s || !bytes.Equal(rest, tt.rest) {
+			t.Errorf("readString(% x) = %q, % x, %v", tt.b, s, rest, err)
+		}
+	}
+}
+
+// broker accepts a single connection on ln, replying to CONNECT with
+// connack followed by the packets in extra in a single write, then
+// acknowledging subscriptions. Packets received are sent on the
+// returned channel.
+func broker(ln net.Listener, connack []byte, extra ...[]byte) <-chan []byte {
+	packets := make(chan []byte, 16)
+	go func() {
+		defer close(packets)
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		r := bufio.NewReader(c)
+		for {
+			typ, body, err := readPacket(r)
+			if err != nil {
+				return
+			}
+			packets <- append([]byte{typ}, body...)
+			switch typ >> 4 {
+			case pConnect:
+				b := encode(pConnack<<4, connack)
+				for _, p := range extra {
+					b = append(b, p...)
+				}
+				c.Write(b)
+			case pSubscribe:
+				c.Write(encode(pSuback<<4, append(body[:2:2], 0)))
+			}
+		}
+	}()
+	return packets
+}
+
+func TestClient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	retained := encode(pPublish<<4|1, append(appendString(nil, "a/b"), "hello"...))
+	packets := broker(ln, []byte{0, 0}, retained)
+
+	msgs := make(chan *Message, 1)
+	cl, err := Dial(ln.Addr().String(), &Options{
+		ClientID: "host_test",
+		Username: "user",
+		Password: "secret",
+		Will:     &Message{Topic: "status", Payload: []byte("offline"), Retain: true},
+		Handler:  func(m *Message) { msgs <- m },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []byte
+	want = appendString(want, "MQTT")
+	want = append(want, 4, fCleanSession|fWill|fWillRetain|fUsername|fPassword, 0, 0)
+	want = appendString(want, "host_test")
+	want = appendString(want, "status")
+	want = appendString(want, "offline")
+	want = appendString(want, "user")
+	want = appendString(want, "secret")
+	if p := <-packets; p[0] != pConnect<<4 || !bytes.Equal(p[1:], want) {
+		t.Errorf("CONNECT = % x, want % x", p[1:], want)
+	}
+
+	select {
+	case m := <-msgs:
+		if m.Topic != "a/b" || string(m.Payload) != "hello" || !m.Retain {
+			t.Errorf("message = %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message sent with CONNACK not received")
+	}
+
+	if err = cl.Subscribe("x/#"); err != nil {
+		t.Fatal(err)
+	}
+	want = append(appendUint16(nil, 1), appendString(nil, "x/#")...)
+	want = append(want, 0)
+	if p := <-packets; p[0] != pSubscribe<<4|2 || !bytes.Equal(p[1:], want) {
+		t.Errorf("SUBSCRIBE = %#x % x, want % x", p[0], p[1:], want)
+	}
+
+	if err = cl.Publish(&Message{Topic: "x/y", Payload: []byte("on")}); err != nil {
+		t.Fatal(err)
+	}
+	want = append(appendString(nil, "x/y"), "on"...)
+	if p := <-packets; p[0] != pPublish<<4 || !bytes.Equal(p[1:], want) {
+		t.Errorf("PUBLISH = %#x % x, want % x", p[0], p[1:], want)
+	}
+
+	if err = cl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if p := <-packets; p[0] != pDisconnect<<4 {
+		t.Errorf("got packet %#x, want DISCONNECT", p[0])
+	}
+	if err = cl.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestConnectRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	broker(ln, []byte{0, 5})
+
+	if _, err = Dial(ln.Addr().String(), &Options{ClientID: "test"}); err == nil {
+		t.Fatal("Dial succeeded")
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package notify implements a queue of notifications that interrupt the
+// contents of the display until acknowledged.
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// Notification is a message shown by a Queue. Notifications with a
+// higher Priority are shown first. If TTL is non-zero, the notification
+// is removed once it expires; otherwise it remains until acknowledged.
+// Posting a notification with the same non-empty Key as a pending
+// notification replaces it. If Flash is set, the backlight flashes while
+// the notification is shown.
+type Notification struct {
+	Key      string
+	Text     string
+	Priority int
+	TTL      time.Duration
+	Flash    bool
+
+	posted time.Time
+}
+
+func (n *Notification) expired(now time.Time) bool {
+	return n.TTL > 0 && !now.Before(n.posted.Add(n.TTL))
+}
+
+// Screen is implemented by content interrupted by notifications, such
+// as a screen.Manager. Suspend is called before the first notification
+// is shown and must not return until the display and input are no longer
+// in use; Resume is called once all notifications have been dismissed.
+type Screen interface {
+	Suspend()
+	Resume()
+}
+
+// Queue shows pending notifications on the display. The notification
+// with the highest priority is shown along with a count of pending
+// notifications; pressing Enter acknowledges it. Once all notifications
+// have been acknowledged or have expired, the backlight is set to
+// Backlight and Screen is resumed. If Screen is nil, the cursor is hidden
+// while notifications are shown, and the previous contents of the
+// display, custom glyphs, and cursor are restored afterward.
+type Queue struct {
+	FlashInterval time.Duration
+	Backlight     smclcd.Backlight
+
+	LCD    *smclcd.LCD
+	Input  *smclcd.GestureReader
+	Screen Screen
+
+	mu    sync.Mutex
+	items []*Notification
+	wake  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+func New(l *smclcd.LCD, input *smclcd.GestureReader) *Queue {
+	return &Queue{
+		FlashInterval: 500 * time.Millisecond,
+		Backlight:     smclcd.BacklightOn,
+		LCD:           l,
+		Input:         input,
+		wake:          make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+}
+
+// Post adds n to the queue. It is safe to call Post concurrently with Run.
+func (q *Queue) Post(n *Notification) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n.posted = time.Now()
+	q.remove(n.Key)
+	q.items = append(q.items, n)
+	sort.SliceStable(q.items, func(i, j int) bool {
+		return q.items[i].Priority > q.items[j].Priority
+	})
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Remove removes the notification posted with key, if any.
+func (q *Queue) Remove(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.remove(key)
+}
+
+func (q *Queue) remove(key string) {
+	if key == "" {
+		return
+	}
+	for i, n := range q.items {
+		if n.Key == key {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Len returns the number of pending notifications.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// top removes expired notifications and returns the notification to be
+// shown along with the number pending and the time the next one expires.
+func (q *Queue) top(now time.Time) (n *Notification, count int, expires time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := q.items[:0]
+	for _, v := range q.items {
+		if v.expired(now) {
+			continue
+		}
+		if v.TTL > 0 {
+			if t := v.posted.Add(v.TTL); expires.IsZero() || t.Before(expires) {
+				expires = t
+			}
+		}
+		items = append(items, v)
+	}
+	q.items = items
+	if len(items) > 0 {
+		n = items[0]
+	}
+	return n, len(items), expires
+}
+
+func (q *Queue) ack(n *Notification) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, v := range q.items {
+		if v == n {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Run shows notifications as they are posted until Close is called or
+// an error occurs reading input or updating the display.
+func (q *Queue) Run() (err error) {
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-q.wake:
+			if err = q.show(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Close stops Run, dismissing the notification being shown, if any.
+// Pending notifications remain queued.
+func (q *Queue) Close() {
+	q.once.Do(func() {
+		close(q.done)
+		q.Input.Wake()
+	})
+}
+
+func (q *Queue) closed() bool {
+	select {
+	case <-q.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *Queue) show() (err error) {
+	var saved smclcd.State
+	var cur *smclcd.Frame
+	if n, _, _ := q.top(time.Now()); n == nil {
+		return
+	}
+	if q.Screen != nil {
+		q.Screen.Suspend()
+		defer q.Screen.Resume()
+	} else {
+		if saved, err = q.LCD.ReadState(); err != nil {
+			return
+		}
+		if err = q.LCD.SetCursor(smclcd.CursorOff); err != nil {
+			return
+		}
+		cur = &saved.Frame
+	}
+
+	var render = q.LCD.NewRenderer()
+	defer render.Close()
+
+	var light = q.Backlight
+	var nextFlash time.Time
+	for !q.closed() {
+		now := time.Now()
+		n, count, expires := q.top(now)
+		if n == nil {
+			break
+		}
+
+		var f smclcd.Frame
+		if f, err = render.Render(n.Text); err != nil {
+			return
+		}
+		if count > 1 {
+			s := fmt.Sprintf("[%d]", count)
+			copy(f.Line(0)[smclcd.Columns-len(s):], s)
+		}
+		if err = q.LCD.DrawFrame(cur, &f); err != nil {
+			return
+		}
+		cur = &f
+
+		var wait = time.Second
+		if n.Flash && q.FlashInterval > 0 {
+			if !now.Before(nextFlash) {
+				light ^= smclcd.BacklightOn
+				if err = q.LCD.SetBacklight(light); err != nil {
+					return
+				}
+				nextFlash = now.Add(q.FlashInterval)
+			}
+			wait = time.Until(nextFlash)
+		} else if light != smclcd.BacklightOn {
+			light = smclcd.BacklightOn
+			if err = q.LCD.SetBacklight(light); err != nil {
+				return
+			}
+		}
+		if d := time.Until(expires); !expires.IsZero() && d < wait {
+			wait = d
+		}
+
+		var e smclcd.GestureEvent
+		if e, err = q.Input.NextTimeout(wait); err == smclcd.ErrTimeout {
+			continue
+		} else if err != nil {
+			return
+		}
+		if e.Gesture == smclcd.GestureClick && e.Code == smclcd.KeyEnter {
+			q.ack(n)
+		}
+	}
+
+	if q.Screen == nil {
+		render.Close()
+		if err = q.restore(&saved); err != nil {
+			return
+		}
+	}
+	return q.LCD.SetBacklight(q.Backlight)
+}
+
+// restore returns the display to the state s, reprogramming glyph slots
+// that were replaced while notifications were shown.
+func (q *Queue) restore(s *smclcd.State) error {
+	cur, err := q.LCD.ReadState()
+	if err != nil {
+		return err
+	}
+	for i, g := range s.Glyphs {
+		if g != cur.Glyphs[i] {
+			if err = q.LCD.SetGlyph(i, g); err != nil {
+				return err
+			}
+		}
+	}
+	if err = q.LCD.DrawFrame(&cur.Frame, &s.Frame); err != nil {
+		return err
+	}
+	if err = q.LCD.MoveCursor(s.Line, s.Column); err != nil {
+		return err
+	}
+	return q.LCD.SetCursor(s.Cursor)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package notify
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+	"github.com/sstallion/go-smclcd/screen"
+)
+
+// waitWritten waits for s to be written after off, returning the offset
+// following it.
+func waitWritten(t *testing.T, d *fakehid.Device, off int, s string) int {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+		if i := bytes.Index(d.Written()[off:], []byte(s)); i >= 0 {
+			return off + i + len(s)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%q not written", s)
+	return 0
+}
+
+func TestQueueScreen(t *testing.T) {
+	d := fakehid.New(smclcd.ErrTimeout)
+	l := smclcd.New(d)
+	input := smclcd.NewGestureReader(smclcd.NewKeyFilter(l))
+	defer input.Close()
+
+	m := screen.New(l, input, &screen.Page{
+		Render: func() (string, error) { return "page", nil },
+	})
+	m.Interval = 0
+	go m.Run()
+	off := waitWritten(t, d, 0, "page")
+
+	q := New(l, input)
+	q.Screen = m
+	errc := make(chan error, 1)
+	go func() { errc <- q.Run() }()
+
+	q.Post(&Notification{Text: "alert", TTL: 100 * time.Millisecond})
+	off = waitWritten(t, d, off, "alert")
+	waitWritten(t, d, off, "page")
+
+	q.Close()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("Run() = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+}
+
+// stubScreen counts calls to Suspend and Resume.
+type stubScreen struct {
+	mu                 sync.Mutex
+	suspended, resumed int
+}
+
+func (s *stubScreen) Suspend() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suspended++
+}
+
+func (s *stubScreen) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resumed++
+}
+
+func TestQueueClose(t *testing.T) {
+	l := smclcd.New(fakehid.New(smclcd.ErrTimeout))
+	input := smclcd.NewGestureReader(smclcd.NewKeyFilter(l))
+	defer input.Close()
+
+	q := New(l, input)
+	s := &stubScreen{}
+	q.Screen = s
+	errc := make(chan error, 1)
+	go func() { errc <- q.Run() }()
+
+	q.Post(&Notification{Text: "pending"})
+	time.Sleep(50 * time.Millisecond)
+	q.Close()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("Run() = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+	if s.suspended != 1 || s.resumed != 1 {
+		t.Errorf("Suspend, Resume called %d, %d times, want 1, 1", s.suspended, s.resumed)
+	}
+	if n := q.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+}
+
+func TestQueueRestore(t *testing.T) {
+	d := fakehid.New(smclcd.ErrTimeout)
+	l := smclcd.New(d)
+	input := smclcd.NewGestureReader(smclcd.NewKeyFilter(l))
+	defer input.Close()
+
+	// Fill every glyph slot so that rendering a notification must
+	// replace one shown on the display.
+	var glyphs [smclcd.GlyphSlots]smclcd.Glyph
+	for i := range glyphs {
+		glyphs[i] = smclcd.Glyph{byte(i + 1), 0x1f}
+		if err := l.SetGlyph(i, glyphs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "\x00\x01 saved" + string(bytes.Repeat([]byte(" "), 2*smclcd.Columns-8))
+	if _, err := l.WriteAt([]byte(want), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.MoveCursor(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetCursor(smclcd.CursorBlock); err != nil {
+		t.Fatal(err)
+	}
+
+	q := New(l, input)
+	errc := make(chan error, 1)
+	go func() { errc <- q.Run() }()
+	defer func() {
+		q.Close()
+		if err := <-errc; err != nil {
+			t.Errorf("Run() = %v", err)
+		}
+	}()
+
+	q.Post(&Notification{Text: "café"})
+	waitWritten(t, d, 0, "caf")
+	if _, _, state := d.Cursor(); state != byte(smclcd.CursorOff) {
+		t.Errorf("cursor = %d while shown, want %d", state, smclcd.CursorOff)
+	}
+	replaced := false
+	for i, g := range glyphs {
+		replaced = replaced || d.Glyph(i) != g
+	}
+	if !replaced {
+		t.Fatal("notification did not replace a glyph slot")
+	}
+
+	d.Key(byte(smclcd.KeyEnter), 1)
+	d.Key(byte(smclcd.KeyEnter), 0)
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, _, state := d.Cursor(); d.Text() == want && state == byte(smclcd.CursorBlock) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("display = %q, want %q", d.Text(), want)
+		}
+	}
+	for i, g := range glyphs {
+		if d.Glyph(i) != g {
+			t.Errorf("glyph %d = %v, want %v", i, d.Glyph(i), g)
+		}
+	}
+	if y, x, _ := d.Cursor(); y != 1 || x != 3 {
+		t.Errorf("cursor at %d, %d, want 1, 3", y, x)
+	}
+}
@@ -114,8 +114,8 @@ package smclcd
 //	    04 Enter
 //	    05 Cancel
 //	 YY Key Event:
-//	    00 Key Pressed
-//	    01 Key Released
+//	    00 Key Released
+//	    01 Key Pressed
 //	 CK Checksum
 //
 // 3.  Set Backlight (`07`)
@@ -160,6 +160,7 @@ const (
 	pCursor    = 0x0c
 	pCursorPos = 0x80
 	pCursorLn  = 0x40
+	pCGRAM     = 0x40
 )
 
 func checksum(b []byte) byte {
@@ -0,0 +1,115 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+type Align byte
+
+//go:generate stringer -type Align -trimprefix=Align
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Region describes a rectangular area of the display. Text written to a
+// region is clipped, padded, and aligned to Width columns on each of its
+// Height lines; cells outside of the region are left untouched.
+type Region struct {
+	Y, X          int
+	Width, Height int
+	Align         Align
+}
+
+func (r Region) check() error {
+	switch {
+	case r.Y < 0 || r.X < 0:
+		return errors.New("region: negative position")
+	case r.Width <= 0 || r.Height <= 0:
+		return errors.New("region: empty size")
+	case r.Y+r.Height > Lines || r.X+r.Width > Columns:
+		return errors.New("region: out of bounds")
+	default:
+		return nil
+	}
+}
+
+// align clips and pads s to width cells, one per rune, as returned by
+// Cells.
+func align(s string, width int, a Align) []byte {
+	cells := Cells(s)
+	if len(cells) > width {
+		cells = cells[:width]
+	}
+	b := bytes.Repeat([]byte(" "), width)
+	switch a {
+	case AlignCenter:
+		copy(b[(width-len(cells))/2:], cells)
+	case AlignRight:
+		copy(b[width-len(cells):], cells)
+	default:
+		copy(b, cells)
+	}
+	return b
+}
+
+// SetRegion writes text to the region r. Lines of text are separated by
+// newlines; missing lines are cleared. The cursor position is preserved.
+func (l *LCD) SetRegion(r Region, text string) error {
+	return l.setRegion(r, text, printable)
+}
+
+func (l *LCD) setRegion(r Region, text string, cells func([]byte) []byte) (err error) {
+	if err = r.check(); err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < r.Height; i++ {
+		var s string
+		if i < len(lines) {
+			s = lines[i]
+		}
+		off := (r.Y+i)*Columns + r.X
+		b := cells(align(s, r.Width, r.Align))
+		if _, err = l.writeAt(b, off); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// SetLine replaces the contents of line n with text aligned by a.
+func (l *LCD) SetLine(n int, text string, a Align) error {
+	return l.SetRegion(Region{Y: n, Width: Columns, Height: 1, Align: a}, text)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import "testing"
+
+func TestAlign(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		a     Align
+		want  string
+	}{
+		{"abc", 5, AlignLeft, "abc  "},
+		{"abc", 5, AlignCenter, " abc "},
+		{"abc", 5, AlignRight, "  abc"},
+		{"abcdef", 4, AlignLeft, "abcd"},
+		{"abcdef", 4, AlignRight, "abcd"},
+		{"日本語", 5, AlignRight, "  ???"},
+		{"日本語日本語", 4, AlignLeft, "????"},
+		{"25\xdfC", 6, AlignLeft, "25\xdfC  "},
+	}
+	for _, tt := range tests {
+		if b := align(tt.s, tt.width, tt.a); string(b) != tt.want {
+			t.Errorf("align(%q, %d, %v) = %q, want %q", tt.s, tt.width, tt.a, b, tt.want)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package relay forwards the raw report stream of a display over TCP.
+//
+// Reports are exchanged as frames, each consisting of a length byte
+// followed by the report. On connecting, the server sends a hello frame
+// containing a magic string, a flags byte, and a random nonce. The client
+// responds with the HMAC-SHA256 of the nonce keyed by the pre-shared key,
+// or an empty frame if it has no key. If the server requires a key and
+// the response does not match, the connection is closed; otherwise the
+// server sends an "OK" frame and begins relaying reports.
+//
+// The key authenticates clients only; reports are not encrypted. A key is
+// required unless the server listens on a loopback address. Only output
+// reports are accepted from clients.
+package relay
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+const (
+	magic        = "SMCLCD\x01"
+	flagAuth     = 1 << 0
+	nonceLen     = 32
+	okFrame      = "OK"
+	maxReports   = 64
+	pollTimeout  = 100 * time.Millisecond
+	dialTimeout  = 10 * time.Second
+	writeTimeout = 5 * time.Second
+
+	reportLen      = 16
+	outputReportID = 0xbb
+)
+
+// ErrAuth is returned when authentication with the relay fails.
+var ErrAuth = errors.New("relay: authentication failed")
+
+// ErrNoKey is returned by Serve when no key is set and the listener is
+// not bound to a loopback address.
+var ErrNoKey = errors.New("relay: key required for non-loopback address")
+
+var errHello = errors.New("relay: invalid hello")
+
+func writeFrame(w io.Writer, p []byte) error {
+	if len(p) > 255 {
+		return errors.New("relay: frame too large")
+	}
+	_, err := w.Write(append([]byte{byte(len(p))}, p...))
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var n [1]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return nil, err
+	}
+	p := make([]byte, n[0])
+	if _, err := io.ReadFull(r, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ReadKey reads a pre-shared key from the named file. Leading and
+// trailing white space is ignored.
+func ReadKey(name string) ([]byte, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	key := bytes.TrimSpace(b)
+	if len(key) == 0 {
+		return nil, errors.New("relay: empty key file: " + name)
+	}
+	return key, nil
+}
+
+func sign(key, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(nonce)
+	return mac.Sum(nil)
+}
+
+// Server relays reports between a Device and a single client. A new
+// client replaces the current one. Reports received from the device
+// while no client is connected are dropped.
+type Server struct {
+	Key    []byte
+	Device smclcd.Device
+
+	mu   sync.Mutex
+	wmu  sync.Mutex
+	conn net.Conn
+	once sync.Once
+	err  error
+}
+
+func NewServer(device smclcd.Device, key []byte) *Server {
+	return &Server{Key: key, Device: device}
+}
+
+// Serve accepts connections on ln until an error occurs accepting a
+// connection or reading from the device. If no key is set, ln must be
+// bound to a loopback address.
+func (s *Server) Serve(ln net.Listener) error {
+	if len(s.Key) == 0 && !isLoopback(ln.Addr()) {
+		return ErrNoKey
+	}
+	done := make(chan struct{})
+	s.once.Do(func() {
+		go func() {
+			s.pump()
+			close(done)
+			ln.Close()
+		}()
+	})
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			select {
+			case <-done:
+				return s.err
+			default:
+				return err
+			}
+		}
+		go s.serveConn(c)
+	}
+}
+
+func isLoopback(addr net.Addr) bool {
+	if a, ok := addr.(*net.TCPAddr); ok {
+		return a.IP.IsLoopback()
+	}
+	return false
+}
+
+// pump forwards reports from the device to the current client. A client
+// that does not accept a report within writeTimeout is disconnected so
+// that it cannot stall the device.
+func (s *Server) pump() {
+	for {
+		b := make([]byte, 64)
+		n, err := s.Device.ReadWithTimeout(b, pollTimeout)
+		if err == smclcd.ErrTimeout {
+			continue
+		} else if err != nil {
+			s.err = err
+			return
+		}
+		s.mu.Lock()
+		c := s.conn
+		s.mu.Unlock()
+		if c != nil {
+			c.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if writeFrame(c, b[:n]) != nil {
+				c.Close()
+			}
+		}
+	}
+}
+
+func (s *Server) serveConn(c net.Conn) {
+	defer c.Close()
+	if err := s.handshake(c); err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn = c
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		if s.conn == c {
+			s.conn = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	for {
+		p, err := readFrame(c)
+		if err != nil {
+			return
+		}
+		if len(p) != reportLen || p[0] != outputReportID {
+			smclcd.DebugLog.Printf("relay: invalid report from %s", c.RemoteAddr())
+			return
+		}
+		s.wmu.Lock()
+		_, err = s.Device.Write(p)
+		s.wmu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handshake(c net.Conn) error {
+	c.SetDeadline(time.Now().Add(dialTimeout))
+	defer c.SetDeadline(time.Time{})
+
+	nonce := make([]byte, nonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	var flags byte
+	if len(s.Key) > 0 {
+		flags |= flagAuth
+	}
+	hello := append(append([]byte(magic), flags), nonce...)
+	if err := writeFrame(c, hello); err != nil {
+		return err
+	}
+	mac, err := readFrame(c)
+	if err != nil {
+		return err
+	}
+	if len(s.Key) > 0 && !hmac.Equal(mac, sign(s.Key, nonce)) {
+		return ErrAuth
+	}
+	return writeFrame(c, []byte(okFrame))
+}
+
+// Conn is a connection to a relay. It implements smclcd.Device.
+type Conn struct {
+	c       net.Conn
+	wmu     sync.Mutex
+	reports chan []byte
+	err     error
+}
+
+// Open opens the display served by the relay listening on addr. If key
+// is non-empty, it is used to authenticate with the relay.
+func Open(addr string, key []byte) (*smclcd.LCD, error) {
+	conn, err := Dial(addr, key)
+	if err != nil {
+		return nil, err
+	}
+	return smclcd.New(conn), nil
+}
+
+// OpenURL is like Open, but takes a URL of the form tcp://host:port. Keys
+// are not accepted in the URL, where they would be visible to other
+// users in the process list; use ReadKey instead.
+func OpenURL(rawurl string, key []byte) (*smclcd.LCD, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "tcp" {
+		return nil, errors.New("relay: unsupported scheme: " + u.Scheme)
+	}
+	if u.User != nil {
+		return nil, errors.New("relay: key in URL not supported")
+	}
+	return Open(u.Host, key)
+}
+
+// Dial connects to the relay listening on addr. If key is non-empty, it
+// is used to authenticate with the relay.
+func Dial(addr string, key []byte) (*Conn, error) {
+	c, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err = handshake(c, key); err != nil {
+		c.Close()
+		return nil, err
+	}
+	conn := &Conn{c: c, reports: make(chan []byte, maxReports)}
+	go conn.recv()
+	return conn, nil
+}
+
+func handshake(c net.Conn, key []byte) error {
+	c.SetDeadline(time.Now().Add(dialTimeout))
+	defer c.SetDeadline(time.Time{})
+
+	hello, err := readFrame(c)
+	if err != nil {
+		return err
+	}
+	if len(hello) != len(magic)+1+nonceLen || string(hello[:len(magic)]) != magic {
+		return errHello
+	}
+	flags, nonce := hello[len(magic)], hello[len(magic)+1:]
+	var mac []byte
+	if len(key) > 0 {
+		mac = sign(key, nonce)
+	} else if flags&flagAuth != 0 {
+		return ErrAuth
+	}
+	if err = writeFrame(c, mac); err != nil {
+		return err
+	}
+	ok, err := readFrame(c)
+	if err == io.EOF || (err == nil && string(ok) != okFrame) {
+		return ErrAuth
+	}
+	return err
+}
+
+func (conn *Conn) recv() {
+	for {
+		p, err := readFrame(conn.c)
+		if err != nil {
+			conn.err = err
+			close(conn.reports)
+			return
+		}
+		select {
+		case conn.reports <- p:
+			continue
+		default:
+		}
+
+		// Drop the oldest report to make room; replies to commands are
+		// read promptly, so it is most likely an unclaimed key event.
+		select {
+		case <-conn.reports:
+			smclcd.DebugLog.Printf("relay: dropped report")
+		default:
+		}
+		conn.reports <- p
+	}
+}
+
+func (conn *Conn) Write(p []byte) (int, error) {
+	conn.wmu.Lock()
+	defer conn.wmu.Unlock()
+	if err := writeFrame(conn.c, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ReadWithTimeout receives the next report relayed from the device,
+// returning smclcd.ErrTimeout if none is received before timeout.
+func (conn *Conn) ReadWithTimeout(p []byte, timeout time.Duration) (int, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case b, ok := <-conn.reports:
+		if !ok {
+			return 0, conn.err
+		}
+		return copy(p, b), nil
+	case <-timer.C:
+		return 0, smclcd.ErrTimeout
+	}
+}
+
+func (conn *Conn) Close() error {
+	return conn.c.Close()
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package relay
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+func serve(t *testing.T, key []byte) (*fakehid.Device, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := fakehid.New(smclcd.ErrTimeout)
+	go NewServer(d, key).Serve(ln)
+	t.Cleanup(func() {
+		ln.Close()
+		d.Close()
+	})
+	return d, ln.Addr().String()
+}
+
+func TestHandshake(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverKey string
+		clientKey string
+		err       error
+	}{
+		{"no key", "", "", nil},
+		{"matching key", "secret", "secret", nil},
+		{"unused key", "", "secret", nil},
+		{"wrong key", "secret", "guess", ErrAuth},
+		{"missing key", "secret", "", ErrAuth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, addr := serve(t, []byte(tt.serverKey))
+			conn, err := Dial(addr, []byte(tt.clientKey))
+			if err != tt.err {
+				t.Fatalf("Dial() error = %v, want %v", err, tt.err)
+			}
+			if conn != nil {
+				conn.Close()
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	d, addr := serve(t, []byte("secret"))
+	name := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(name, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := ReadKey(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := OpenURL("tcp://"+addr, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if s, err := l.Version(); err != nil || s != "1.0" {
+		t.Errorf("Version() = %q, %v, want %q", s, err, "1.0")
+	}
+	// Reports are relayed in order; reading the display back waits for
+	// the preceding writes to reach the device.
+	if err = l.SetLine(1, "Hello, world", smclcd.AlignCenter); err != nil {
+		t.Fatal(err)
+	}
+	if err = l.SetBacklight(smclcd.BacklightOn); err != nil {
+		t.Fatal(err)
+	}
+	f, err := l.ReadFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "                  Hello, world  "
+	if got := string(f[:]); got != want {
+		t.Errorf("ReadFrame() = %q, want %q", got, want)
+	}
+	if got := d.Text(); got != want {
+		t.Errorf("display = %q, want %q", got, want)
+	}
+	if !d.Backlight() {
+		t.Error("backlight off, want on")
+	}
+
+	d.Key(byte(smclcd.KeyEnter), byte(smclcd.KeyPress))
+	if key, err := l.GetInput(); err != nil || key != (smclcd.Key{Code: smclcd.KeyEnter, Event: smclcd.KeyPress}) {
+		t.Errorf("GetInput() = %v, %v, want Enter press", key, err)
+	}
+}
+
+func TestOpenURLKey(t *testing.T) {
+	_, addr := serve(t, []byte("secret"))
+	if l, err := OpenURL("tcp://:secret@"+addr, nil); err == nil {
+		l.Close()
+		t.Error("OpenURL() with key in URL succeeded, want error")
+	}
+}
+
+func TestInvalidReport(t *testing.T) {
+	tests := []struct {
+		name   string
+		report []byte
+	}{
+		{"empty", []byte{}},
+		{"report ID", []byte{0x00}},
+		{"short", []byte{0xaa, 0x03, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, addr := serve(t, nil)
+			l, err := Open(addr, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+
+			d.Input(tt.report)
+			if _, err = l.GetInput(); err == nil {
+				t.Error("GetInput() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestInvalidFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"short", []byte{0xbb, 0x07, 0x01}},
+		{"input report", append([]byte{0xaa}, make([]byte, 15)...)},
+		{"long", append([]byte{0xbb}, make([]byte, 254)...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, addr := serve(t, nil)
+			conn, err := Dial(addr, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			if _, err = conn.Write(tt.frame); err != nil {
+				t.Fatal(err)
+			}
+			for {
+				if _, err = conn.ReadWithTimeout(make([]byte, 64), time.Second); err != smclcd.ErrTimeout {
+					break
+				}
+			}
+			if err != io.EOF {
+				t.Errorf("connection not closed: %v", err)
+			}
+		})
+	}
+}
+
+// addrListener is a listener reporting a fixed address.
+type addrListener struct {
+	net.Listener
+	addr net.Addr
+}
+
+func (ln addrListener) Addr() net.Addr {
+	return ln.addr
+}
+
+func TestServeNoKey(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	d := fakehid.New(smclcd.ErrTimeout)
+	defer d.Close()
+
+	addr := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 4000}
+	if err = NewServer(d, nil).Serve(addrListener{ln, addr}); err != ErrNoKey {
+		t.Errorf("Serve() = %v, want %v", err, ErrNoKey)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SetFont sets the glyphs used by Render and RenderFrame. If font is nil,
+// DefaultFont is used.
+func (l *LCD) SetFont(font GlyphSet) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.font = font
+}
+
+// Renderer lays out text on frames, mapping runes missing from the
+// character ROM to custom glyphs. Each Renderer owns the glyph slots
+// allocated for the last frame it rendered, which are released by the
+// next call to Render or by Close. Callers sharing a display should each
+// use their own Renderer so that rendering a frame does not release the
+// glyphs of a frame drawn by another caller.
+type Renderer struct {
+	l     *LCD
+	slots []byte
+}
+
+// NewRenderer returns a Renderer drawing glyphs on l.
+func (l *LCD) NewRenderer() *Renderer {
+	return &Renderer{l: l}
+}
+
+// Render lays out text on a Frame like NewFrame, but maps runes missing
+// from the character ROM to custom glyphs. Glyph slots are allocated for
+// the most frequent runes first; remaining runes are transliterated if
+// all slots are in use. Slots allocated by the previous call remain held
+// while the new frame's slots are allocated and are then released, so
+// Render should be called once per frame drawn with DrawFrame.
+func (r *Renderer) Render(text string) (Frame, error) {
+	r.l.mu.Lock()
+	defer r.l.mu.Unlock()
+	return r.l.renderFrame(text, &r.slots)
+}
+
+// Close releases the glyph slots allocated by the last call to Render.
+func (r *Renderer) Close() {
+	r.l.mu.Lock()
+	defer r.l.mu.Unlock()
+	r.l.freeGlyphs(r.slots)
+	r.slots = nil
+}
+
+// RenderFrame is like Render, using a Renderer shared by all callers of
+// RenderFrame.
+func (l *LCD) RenderFrame(text string) (Frame, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.renderFrame(text, &l.render)
+}
+
+// renderFrame lays out text, replacing the glyph slots held in owned.
+func (l *LCD) renderFrame(text string, owned *[]byte) (f Frame, err error) {
+	font := l.font
+	if font == nil {
+		font = DefaultFont
+	}
+
+	var cells [len(f)]rune
+	lines := strings.Split(text, "\n")
+	for y := 0; y < Lines; y++ {
+		var line []rune
+		if y < len(lines) {
+			line = []rune(lines[y])
+		}
+		for x := 0; x < Columns; x++ {
+			r := ' '
+			if x < len(line) {
+				r = line[x]
+			}
+			cells[y*Columns+x] = r
+		}
+	}
+
+	// Count runes needing a custom glyph.
+	var count = make(map[rune]int)
+	for _, r := range cells {
+		if _, ok := romChar(r); !ok {
+			if _, ok := lookupGlyph(font, r); ok {
+				count[r]++
+			}
+		}
+	}
+	var runes []rune
+	for r := range count {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool {
+		if count[runes[i]] != count[runes[j]] {
+			return count[runes[i]] > count[runes[j]]
+		}
+		return runes[i] < runes[j]
+	})
+
+	// Slots held for the previous frame are released only once the new
+	// frame's slots are allocated, so glyphs still on the display are not
+	// reprogrammed before the new frame is drawn.
+	var slots = make(map[rune]byte)
+	var alloc []byte
+	for _, r := range runes {
+		g, _ := lookupGlyph(font, r)
+		var slot byte
+		if slot, err = l.allocGlyph(g); err != nil {
+			if errors.Is(err, ErrNoGlyphSlots) {
+				err = nil
+				break
+			}
+			l.freeGlyphs(alloc)
+			return
+		}
+		slots[r] = slot
+		alloc = append(alloc, slot)
+	}
+	l.freeGlyphs(*owned)
+	*owned = alloc
+
+	for i, r := range cells {
+		if slot, ok := slots[r]; ok {
+			f[i] = slot
+		} else {
+			f[i] = romCell(r)
+		}
+	}
+	return
+}
+
+// Cells returns the cells displaying text, one per rune. Runes missing
+// from the character ROM are transliterated; custom glyphs are only
+// used by Render and RenderFrame. Bytes that are not valid UTF-8 are
+// passed through, allowing characters in the character ROM to be used
+// directly. Control characters are preserved.
+func Cells(text string) []byte {
+	var b []byte
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, text[0])
+		case r < utf8.RuneSelf && r != '\\':
+			b = append(b, byte(r))
+		default:
+			b = append(b, romCell(r))
+		}
+		text = text[size:]
+	}
+	return b
+}
+
+// romCell returns the character in the character ROM displaying r,
+// transliterating r if it is missing.
+func romCell(r rune) byte {
+	if c, ok := romChar(r); ok {
+		return c
+	} else if c, ok := translit[r]; ok {
+		return c
+	}
+	return '?'
+}
+
+// romChar returns the character in the character ROM displaying r. The
+// backslash is missing; its code displays a yen sign.
+func romChar(r rune) (byte, bool) {
+	if r >= 0x20 && r < 0x7f && r != '\\' {
+		return byte(r), true
+	}
+	c, ok := romChars[r]
+	return c, ok
+}
+
+func lookupGlyph(font GlyphSet, r rune) (Glyph, bool) {
+	if g, ok := font[r]; ok {
+		return g, true
+	}
+	// Fall back to capitals for scripts without lowercase glyphs.
+	g, ok := font[unicode.ToUpper(r)]
+	return g, ok
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRendererOwnership(t *testing.T) {
+	l := New(newFakeDevice())
+	a, b := l.NewRenderer(), l.NewRenderer()
+
+	fa, err := a.Render("é")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slot := fa[0]
+	if slot >= GlyphSlots {
+		t.Fatalf("Render() cell = %#x, want a glyph slot", slot)
+	}
+	want := l.glyphs[slot].g
+
+	// Rendering with another Renderer must not release a's glyphs.
+	for _, s := range []string{"è", "ß", "ç"} {
+		if _, err := b.Render(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := l.glyphs[slot]; s.refs != 1 || s.g != want {
+		t.Errorf("slot %d refs = %d, changed = %v, want 1, false", slot, s.refs, s.g != want)
+	}
+
+	a.Close()
+	if refs := l.glyphs[slot].refs; refs != 0 {
+		t.Errorf("slot %d refs after Close = %d, want 0", slot, refs)
+	}
+}
+
+func TestRenderKeepsPrevious(t *testing.T) {
+	d := newFakeDevice()
+	l := New(d)
+	r := l.NewRenderer()
+
+	// Hold all but one slot.
+	var held []byte
+	for i := 0; i < GlyphSlots-1; i++ {
+		slot, err := l.AllocGlyph(Glyph{byte(i + 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		held = append(held, slot)
+	}
+
+	f, err := r.Render("é")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := f[0]
+	want := d.Glyph(int(prev))
+
+	// The glyph shown by the previous frame must not be reprogrammed
+	// before the new frame is drawn, leaving no slot for the next.
+	if f, err = r.Render("è"); err != nil {
+		t.Fatal(err)
+	}
+	if f[0] != 'e' {
+		t.Errorf("Render(\"è\") cell = %#x, want 'e'", f[0])
+	}
+	if g := d.Glyph(int(prev)); g != want {
+		t.Errorf("slot %d reprogrammed to %v, want %v", prev, g, want)
+	}
+	if refs := l.glyphs[prev].refs; refs != 0 {
+		t.Errorf("slot %d refs = %d, want 0", prev, refs)
+	}
+
+	// Runes in both frames keep their slot.
+	if f, err = r.Render("è"); err != nil {
+		t.Fatal(err)
+	}
+	g := f[0]
+	if g >= GlyphSlots {
+		t.Fatalf("Render(\"è\") cell = %#x, want a glyph slot", g)
+	}
+	l.FreeGlyph(held[0])
+	if f, err = r.Render("èé"); err != nil {
+		t.Fatal(err)
+	}
+	if f[0] != g || l.glyphs[g].refs != 1 {
+		t.Errorf("è moved from slot %d to %d, refs = %d", g, f[0], l.glyphs[f[0]].refs)
+	}
+}
+
+func TestRenderBackslash(t *testing.T) {
+	l := New(newFakeDevice())
+	f, err := l.NewRenderer().Render("\\¥")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f[0] >= GlyphSlots || l.glyphs[f[0]].g != DefaultFont['\\'] {
+		t.Errorf("Render(\"\\\\\") cell = %#x, want backslash glyph", f[0])
+	}
+	if f[1] != 0x5c {
+		t.Errorf("Render(\"¥\") cell = %#x, want 0x5c", f[1])
+	}
+	if s := f.Text(); !strings.HasPrefix(s, "?¥") {
+		t.Errorf("Text() = %q, want prefix %q", s, "?¥")
+	}
+}
+
+func TestCells(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"abc", "abc"},
+		{"25°C", "25\xdfC"},
+		{"café", "cafe"},
+		{"Привет", "Ppivet"},
+		{"日本", "??"},
+		{"25\xdfC", "25\xdfC"},
+		{"a\nb", "a\nb"},
+		{"C:\\tmp", "C:/tmp"},
+		{"¥100", "\x5c100"},
+	}
+	for _, tt := range tests {
+		if b := Cells(tt.text); string(b) != tt.want {
+			t.Errorf("Cells(%q) = %q, want %q", tt.text, b, tt.want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package screen manages a set of pages shown on the display in turn.
+package screen
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// Page is a unit of content shown by a Manager. Render returns the text
+// of the page, with lines separated by newlines. If Refresh is non-zero,
+// the page is rendered again every Refresh while it is visible.
+type Page struct {
+	Name    string
+	Render  func() (string, error)
+	Refresh time.Duration
+}
+
+// Manager shows Pages on the display, rotating to the next page every
+// Interval. Left and Right flip between pages manually, which pauses
+// rotation for Pause. Only the visible page is rendered, and only cells
+// that changed are redrawn. Changing pages uses Transition, displaying
+// each intermediate frame for Step; slides are reversed when flipping
+// to the previous page.
+//
+// Input may be shared with other users of the display, such as a
+// notification queue, provided they only read from it while the Manager
+// is suspended.
+type Manager struct {
+	Pages      []*Page
+	Interval   time.Duration
+	Pause      time.Duration
+	Transition smclcd.Transition
+	Step       time.Duration
+
+	LCD   *smclcd.LCD
+	Input *smclcd.GestureReader
+
+	cur    int
+	dir    int
+	shown  int
+	frame  *smclcd.Frame
+	render *smclcd.Renderer
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	running   bool
+	suspended bool
+	paused    bool
+}
+
+func New(l *smclcd.LCD, input *smclcd.GestureReader, pages ...*Page) *Manager {
+	m := &Manager{
+		Pages:    pages,
+		Interval: 10 * time.Second,
+		Pause:    time.Minute,
+		Step:     50 * time.Millisecond,
+		LCD:      l,
+		Input:    input,
+	}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// Suspend pauses Run so that the display and Input may be used by
+// another caller, returning once Run is no longer drawing or reading
+// input. Resume redraws the current page and continues rotation. If Run
+// has not started, it waits for Resume before drawing.
+func (m *Manager) Suspend() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.suspended = true
+	m.Input.Wake()
+	for m.running && !m.paused {
+		m.cond.Wait()
+	}
+}
+
+// Resume continues Run after a call to Suspend.
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.suspended = false
+	m.cond.Broadcast()
+}
+
+// wait blocks while the Manager is suspended, reporting whether it was.
+func (m *Manager) wait() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.suspended {
+		return false
+	}
+	m.paused = true
+	m.cond.Broadcast()
+	for m.suspended {
+		m.cond.Wait()
+	}
+	m.paused = false
+	return true
+}
+
+func (m *Manager) setRunning(running bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = running
+	m.cond.Broadcast()
+}
+
+// Run shows pages until an error occurs reading input or updating the
+// display.
+func (m *Manager) Run() (err error) {
+	m.setRunning(true)
+	defer m.setRunning(false)
+
+	m.wait()
+	if err = m.LCD.SetCursor(smclcd.CursorOff); err != nil {
+		return
+	}
+	if len(m.Pages) == 0 {
+		return m.LCD.Clear()
+	}
+
+	m.render = m.LCD.NewRenderer()
+	defer m.render.Close()
+
+	m.shown = -1
+	var now = time.Now()
+	var nextRotate, nextRefresh time.Time
+	if m.Interval > 0 {
+		nextRotate = now.Add(m.Interval)
+	}
+	for {
+		if m.wait() {
+			m.frame, m.shown = nil, m.cur
+		}
+		if err = m.draw(); err != nil {
+			return
+		}
+		if r := m.Pages[m.cur].Refresh; r > 0 {
+			nextRefresh = time.Now().Add(r)
+		} else {
+			nextRefresh = time.Time{}
+		}
+
+		for {
+			var e smclcd.GestureEvent
+			var deadline = earliest(nextRotate, nextRefresh)
+			if deadline.IsZero() {
+				e, err = m.Input.Next()
+			} else {
+				e, err = m.Input.NextTimeout(time.Until(deadline))
+			}
+
+			now = time.Now()
+			if err == smclcd.ErrTimeout {
+				if m.wait() {
+					// The display was used while suspended; redraw
+					// the current page in full.
+					m.frame, m.shown = nil, m.cur
+					if m.Interval > 0 {
+						nextRotate = time.Now().Add(m.Interval)
+					}
+					break
+				}
+				if !nextRotate.IsZero() && !now.Before(nextRotate) {
+					m.flip(1)
+					nextRotate = now.Add(m.Interval)
+				}
+				break
+			} else if err != nil {
+				return
+			}
+
+			if e.Gesture != smclcd.GestureClick && e.Gesture != smclcd.GestureRepeat {
+				continue
+			}
+			switch e.Code {
+			case smclcd.KeyLeft:
+				m.flip(-1)
+			case smclcd.KeyRight:
+				m.flip(1)
+			default:
+				continue
+			}
+			if m.Interval > 0 {
+				d := m.Pause
+				if d < m.Interval {
+					d = m.Interval
+				}
+				nextRotate = now.Add(d)
+			}
+			break
+		}
+	}
+}
+
+func (m *Manager) flip(n int) {
+	m.cur = (m.cur + n + len(m.Pages)) % len(m.Pages)
+	m.dir = n
+}
+
+func (m *Manager) draw() error {
+	s, err := m.Pages[m.cur].Render()
+	if err != nil {
+		s = "Error:\n" + err.Error()
+	}
+	f, err := m.render.Render(s)
+	if err != nil {
+		return err
+	}
+	if m.cur == m.shown {
+		err = m.LCD.DrawFrame(m.frame, &f)
+	} else {
+		t := m.Transition
+		if m.dir < 0 {
+			switch t {
+			case smclcd.TransitionSlideLeft:
+				t = smclcd.TransitionSlideRight
+			case smclcd.TransitionSlideRight:
+				t = smclcd.TransitionSlideLeft
+			}
+		}
+		err = m.LCD.DrawTransition(m.frame, &f, t, m.Step)
+	}
+	if err != nil {
+		return err
+	}
+	m.frame = &f
+	m.shown = m.cur
+	return nil
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
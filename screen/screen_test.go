@@ -0,0 +1,275 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package screen
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+// text returns a static page render function.
+func text(s string) func() (string, error) {
+	return func() (string, error) { return s, nil }
+}
+
+// display returns the display contents showing s on the first line.
+func display(s string) string {
+	return s + strings.Repeat(" ", smclcd.Lines*smclcd.Columns-len(s))
+}
+
+// click presses and releases code.
+func click(d *fakehid.Device, code smclcd.KeyCode) {
+	d.Key(byte(code), byte(smclcd.KeyPress))
+	d.Key(byte(code), byte(smclcd.KeyRelease))
+}
+
+// waitText waits for the display to show want.
+func waitText(t *testing.T, d *fakehid.Device, want string) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+		if d.Text() == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("display = %q, want %q", d.Text(), want)
+}
+
+// start runs a Manager showing pages until the test ends.
+// newManager returns a Manager for pages that shows each page until
+// flipped, drawing on an emulated display.
+func newManager(t *testing.T, pages ...*Page) (*Manager, *fakehid.Device) {
+	t.Helper()
+	d := fakehid.New(smclcd.ErrTimeout)
+	t.Cleanup(func() { d.Close() })
+	l := smclcd.New(d)
+	m := New(l, smclcd.NewGestureReader(l), pages...)
+	m.Interval = 0
+	m.Step = time.Millisecond
+	return m, d
+}
+
+// run runs m until the test completes.
+func run(t *testing.T, m *Manager) {
+	t.Helper()
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run() }()
+	t.Cleanup(func() {
+		m.Input.Close()
+		if err := <-errc; err != smclcd.ErrClosed {
+			t.Errorf("Run() = %v, want %v", err, smclcd.ErrClosed)
+		}
+	})
+}
+
+func start(t *testing.T, pages ...*Page) (*Manager, *fakehid.Device) {
+	t.Helper()
+	m, d := newManager(t, pages...)
+	run(t, m)
+	return m, d
+}
+
+func TestFlip(t *testing.T) {
+	_, d := start(t,
+		&Page{Render: text("one")},
+		&Page{Render: text("two")},
+		&Page{Render: text("three")},
+	)
+	waitText(t, d, display("one"))
+	for _, step := range []struct {
+		code smclcd.KeyCode
+		want string
+	}{
+		{smclcd.KeyRight, "two"},
+		{smclcd.KeyRight, "three"},
+		{smclcd.KeyRight, "one"},
+		{smclcd.KeyLeft, "three"},
+		{smclcd.KeyLeft, "two"},
+	} {
+		click(d, step.code)
+		waitText(t, d, display(step.want))
+	}
+}
+
+func TestRotate(t *testing.T) {
+	m, d := newManager(t,
+		&Page{Render: text("one")},
+		&Page{Render: text("two")},
+	)
+	m.Interval = 20 * time.Millisecond
+	run(t, m)
+	waitText(t, d, display("two"))
+	waitText(t, d, display("one"))
+}
+
+func TestTransition(t *testing.T) {
+	m, d := newManager(t,
+		&Page{Render: text("one")},
+		&Page{Render: text("two")},
+	)
+	m.Transition = smclcd.TransitionSlideLeft
+	run(t, m)
+	waitText(t, d, display("one"))
+
+	// want returns the cells written by a transition between pages.
+	want := func(from, to string, tr smclcd.Transition) string {
+		ref := fakehid.New(smclcd.ErrTimeout)
+		l := smclcd.New(ref)
+		prev, f := smclcd.NewFrame(from), smclcd.NewFrame(to)
+		if err := l.DrawFrame(nil, &prev); err != nil {
+			t.Fatal(err)
+		}
+		n := len(ref.Written())
+		if err := l.DrawTransition(&prev, &f, tr, 0); err != nil {
+			t.Fatal(err)
+		}
+		return string(ref.Written()[n:])
+	}
+
+	// Flipping back reverses the direction of slides.
+	for _, step := range []struct {
+		code     smclcd.KeyCode
+		from, to string
+		tr       smclcd.Transition
+	}{
+		{smclcd.KeyRight, "one", "two", smclcd.TransitionSlideLeft},
+		{smclcd.KeyLeft, "two", "one", smclcd.TransitionSlideRight},
+	} {
+		n := len(d.Written())
+		click(d, step.code)
+		waitText(t, d, display(step.to))
+		if got, want := string(d.Written()[n:]), want(step.from, step.to, step.tr); got != want {
+			t.Errorf("%v: wrote %q, want %v %q", step.code, got, step.tr, want)
+		}
+	}
+}
+
+func TestSuspend(t *testing.T) {
+	m, d := start(t, &Page{Render: text("page")})
+	waitText(t, d, display("page"))
+
+	m.Suspend()
+	if err := m.LCD.SetLine(0, "notice", smclcd.AlignLeft); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := d.Text(); got != display("notice") {
+		t.Errorf("display = %q while suspended, want %q", got, display("notice"))
+	}
+
+	// The page is redrawn in full on resuming, although it has not
+	// changed.
+	m.Resume()
+	waitText(t, d, display("page"))
+}
+
+func TestSuspendNotRunning(t *testing.T) {
+	d := fakehid.New(smclcd.ErrTimeout)
+	defer d.Close()
+	l := smclcd.New(d)
+	input := smclcd.NewGestureReader(l)
+	defer input.Close()
+	m := New(l, input)
+
+	done := make(chan struct{})
+	go func() {
+		m.Suspend()
+		m.Resume()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Suspend blocked without Run")
+	}
+}
+
+func TestSuspendDuringRender(t *testing.T) {
+	var rendering, renders int32
+	m, _ := start(t, &Page{
+		Render: func() (string, error) {
+			atomic.StoreInt32(&rendering, 1)
+			defer atomic.StoreInt32(&rendering, 0)
+			atomic.AddInt32(&renders, 1)
+			time.Sleep(time.Millisecond)
+			return "busy", nil
+		},
+		Refresh: time.Millisecond,
+	})
+
+	for i := 0; i < 20; i++ {
+		m.Suspend()
+		busy := atomic.LoadInt32(&rendering) != 0
+		n := atomic.LoadInt32(&renders)
+		time.Sleep(5 * time.Millisecond)
+		n = atomic.LoadInt32(&renders) - n
+		m.Resume()
+		if busy {
+			t.Fatal("rendering after Suspend returned")
+		}
+		if n != 0 {
+			t.Fatalf("rendered %d times while suspended", n)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestRendererGlyphs(t *testing.T) {
+	m, d := start(t, &Page{Render: text("café")})
+	want := display("caf?")
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		if got := d.Text(); got[:3] == "caf" && got[3] < smclcd.GlyphSlots {
+			want = got
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("display = %q, want a glyph for é", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	slot := int(want[3])
+	g := d.Glyph(slot)
+
+	// Another user rendering while the Manager is suspended does not
+	// release the glyphs of the page.
+	m.Suspend()
+	r := m.LCD.NewRenderer()
+	if _, err := r.Render("àèìòù"); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if _, err := r.Render("ÀÈÌÒÙ"); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Glyph(slot); got != g {
+		t.Errorf("glyph %d reprogrammed while in use: %v, want %v", slot, got, g)
+	}
+	r.Close()
+	m.Resume()
+	waitText(t, d, want)
+}
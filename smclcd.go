@@ -25,9 +25,11 @@ package smclcd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"unicode"
+	"sync"
+	"time"
 
 	"github.com/sstallion/go-hid"
 	"github.com/sstallion/go-tools/util"
@@ -85,9 +87,82 @@ const (
 	KeyPress
 )
 
+const (
+	pollInterval = 100 * time.Millisecond // input report poll interval
+	maxPending   = 16                     // unclaimed input reports held
+)
+
+var errInvalidReport = errors.New("input: invalid report")
+
+// Display is the set of operations common to LCD and remote displays,
+// such as those served by the daemon package.
+type Display interface {
+	io.ReadWriteCloser
+	io.Seeker
+	io.ReaderAt
+	io.WriterAt
+	KeyReader
+
+	Version() (string, error)
+	Clear() error
+	Home() error
+	SetCursor(state Cursor) error
+	AdvanceCursor(n int) error
+	MoveCursor(y, x int) error
+	SetBacklight(state Backlight) error
+}
+
+// LCD is a handle to an open display. It is safe for concurrent use by
+// multiple goroutines.
 type LCD struct {
-	device *hid.Device
-	pos    cursor
+	device Device
+
+	mu        sync.Mutex
+	pos       cursor
+	mode      TextMode
+	glyphs    [GlyphSlots]glyphSlot
+	cursor    Cursor
+	backlight Backlight
+
+	big       [len(bigGlyphs)]byte // held until Close
+	bigLoaded bool
+
+	font   GlyphSet
+	render []byte
+
+	rmu     sync.Mutex
+	pending [][]byte
+}
+
+// Device transports HID reports to and from a display. ReadWithTimeout
+// returns ErrTimeout if no report is received before timeout.
+type Device interface {
+	Write(p []byte) (int, error)
+	ReadWithTimeout(p []byte, timeout time.Duration) (int, error)
+	Close() error
+}
+
+// hidDevice adapts a HID device to Device.
+type hidDevice struct {
+	*hid.Device
+}
+
+// HIDDevice returns a Device communicating with a HID device.
+func HIDDevice(device *hid.Device) Device {
+	return hidDevice{device}
+}
+
+func (d hidDevice) ReadWithTimeout(p []byte, timeout time.Duration) (int, error) {
+	n, err := d.Device.ReadWithTimeout(p, timeout)
+	if err == hid.ErrTimeout {
+		err = ErrTimeout
+	}
+	return n, err
+}
+
+// New returns an LCD communicating over device.
+func New(device Device) *LCD {
+	return &LCD{device: device, backlight: BacklightOn}
 }
 
 func Open(serial string) (l *LCD, err error) {
@@ -95,8 +170,7 @@ func Open(serial string) (l *LCD, err error) {
 	if device, err = hid.Open(VendorID, ProductID, serial); err != nil {
 		return
 	}
-	l = &LCD{device: device}
-	return
+	return New(HIDDevice(device)), nil
 }
 
 func OpenFirst() (l *LCD, err error) {
@@ -104,8 +178,7 @@ func OpenFirst() (l *LCD, err error) {
 	if device, err = hid.OpenFirst(VendorID, ProductID); err != nil {
 		return
 	}
-	l = &LCD{device: device}
-	return
+	return New(HIDDevice(device)), nil
 }
 
 func OpenPath(path string) (l *LCD, err error) {
@@ -113,27 +186,57 @@ func OpenPath(path string) (l *LCD, err error) {
 	if device, err = hid.OpenPath(path); err != nil {
 		return
 	}
-	l = &LCD{device: device}
-	return
+	return New(HIDDevice(device)), nil
 }
 
 func (l *LCD) Close() error {
 	return l.device.Close()
 }
 
+// recvInputReport receives the next input report starting with prefix.
+// Reports that do not match are held for concurrent callers waiting on a
+// different prefix, such as GetInput.
 func (l *LCD) recvInputReport(p, prefix []byte) (err error) {
 	prefix = append([]byte{inputReportID}, prefix...)
-	b := make([]byte, inputReportLen)
 	for {
-		if _, err = l.device.Read(b); err != nil {
+		var b []byte
+		if b, err = l.nextInputReport(prefix); err == ErrTimeout {
+			continue
+		} else if err != nil {
 			return
 		}
-		logReport(b)
-		if bytes.HasPrefix(b, prefix) {
-			break
+		copy(p, bytes.TrimPrefix(b[:len(b)-1], prefix))
+		return
+	}
+}
+
+func (l *LCD) nextInputReport(prefix []byte) (b []byte, err error) {
+	l.rmu.Lock()
+	defer l.rmu.Unlock()
+
+	for i, v := range l.pending {
+		if bytes.HasPrefix(v, prefix) {
+			l.pending = append(l.pending[:i], l.pending[i+1:]...)
+			return v, nil
 		}
 	}
-	copy(p, bytes.TrimPrefix(b[:len(b)-1], prefix))
+
+	b = make([]byte, inputReportLen)
+	var n int
+	if n, err = l.device.ReadWithTimeout(b, pollInterval); err != nil {
+		return nil, err
+	}
+	if n != inputReportLen || b[0] != inputReportID {
+		return nil, errInvalidReport
+	}
+	logReport(b)
+	if !bytes.HasPrefix(b, prefix) {
+		if len(l.pending) == maxPending {
+			l.pending = l.pending[1:]
+		}
+		l.pending = append(l.pending, b)
+		return nil, ErrTimeout
+	}
 	return
 }
 
@@ -150,6 +253,9 @@ func (l *LCD) sendOutputReport(p []byte) (err error) {
 }
 
 func (l *LCD) Version() (s string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	prefix := []byte{pVersion}
 	if err = l.sendOutputReport(prefix); err != nil {
 		return
@@ -164,23 +270,48 @@ func (l *LCD) Version() (s string, err error) {
 }
 
 func (l *LCD) Clear() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.clear()
+}
+
+func (l *LCD) clear() error {
 	l.pos.Move(0, 0)
 	b := []byte{pLCD, pControl, pClear}
 	return l.sendOutputReport(b)
 }
 
 func (l *LCD) Home() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	l.pos.Move(0, 0)
 	b := []byte{pLCD, pControl, pHome}
 	return l.sendOutputReport(b)
 }
 
 func (l *LCD) SetCursor(state Cursor) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.setCursor(state)
+}
+
+func (l *LCD) setCursor(state Cursor) (err error) {
 	b := []byte{pLCD, pControl, pCursor + byte(state)}
-	return l.sendOutputReport(b)
+	if err = l.sendOutputReport(b); err != nil {
+		return
+	}
+	l.cursor = state
+	return
 }
 
-func (l *LCD) AdvanceCursor(n int) (err error) {
+func (l *LCD) AdvanceCursor(n int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.advanceCursor(n)
+}
+
+func (l *LCD) advanceCursor(n int) (err error) {
 	if err = l.pos.Advance(n); err != nil {
 		return
 	}
@@ -188,7 +319,13 @@ func (l *LCD) AdvanceCursor(n int) (err error) {
 	return l.sendOutputReport(b)
 }
 
-func (l *LCD) MoveCursor(y, x int) (err error) {
+func (l *LCD) MoveCursor(y, x int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.moveCursor(y, x)
+}
+
+func (l *LCD) moveCursor(y, x int) (err error) {
 	if err = l.pos.Move(y, x); err != nil {
 		return
 	}
@@ -196,7 +333,21 @@ func (l *LCD) MoveCursor(y, x int) (err error) {
 	return l.sendOutputReport(b)
 }
 
-func (l *LCD) Write(p []byte) (n int, err error) {
+// Write writes the cells in p at the cursor according to the text mode.
+// Each byte of p is a character in the character ROM; p is not decoded
+// as UTF-8. Use Cells to convert text, or SetLine, SetRegion, and
+// RenderFrame, which also map runes missing from the character ROM.
+func (l *LCD) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.write(p)
+}
+
+func (l *LCD) write(p []byte) (n int, err error) {
+	if l.mode != 0 {
+		return l.writeText(p)
+	}
+
 	lines := bytes.Split(p, []byte("\n"))
 	for i, line := range lines {
 		var m int
@@ -217,14 +368,25 @@ func (l *LCD) Write(p []byte) (n int, err error) {
 	return
 }
 
+// printable replaces control characters in p, which would otherwise be
+// interpreted as custom glyphs by the display.
+func printable(p []byte) []byte {
+	b := make([]byte, len(p))
+	for i, c := range p {
+		if c < ' ' || c == 0x7f {
+			c = '?'
+		}
+		b[i] = c
+	}
+	return b
+}
+
 func (l *LCD) writeRaw(p []byte) (n int, err error) {
+	return l.writeCells(printable(p))
+}
+
+func (l *LCD) writeCells(p []byte) (n int, err error) {
 	prefix := []byte{pLCD, pWrite}
-	p = bytes.Map(func(r rune) rune {
-		if !unicode.IsPrint(r) {
-			return '?'
-		}
-		return r
-	}, p)
 	for n < len(p) {
 		if err = l.pos.Error(); err != nil {
 			return
@@ -237,14 +399,57 @@ func (l *LCD) writeRaw(p []byte) (n int, err error) {
 		}
 		n += m
 
-		if err = l.AdvanceCursor(m); err != nil {
+		if err = l.advanceCursor(m); err != nil {
 			return
 		}
 	}
 	return
 }
 
+func (l *LCD) writeAt(p []byte, off int) (n int, err error) {
+	pos := l.pos
+	defer func() {
+		if e := l.restoreCursor(pos); err == nil {
+			err = e
+		}
+	}()
+
+	if err = l.moveCursor(off/Columns, off%Columns); err != nil {
+		return
+	}
+	if n, err = l.writeCells(p); err == io.EOF && n == len(p) {
+		err = nil // wrote to end of display
+	}
+	return
+}
+
+// WriteAt writes the cells in p starting at off, numbering cells from the
+// top left with each line following the last, as io.WriterAt. Unlike
+// Write, the text mode is ignored and cells are written raw: bytes below
+// GlyphSlots display custom glyphs and other control characters are not
+// replaced. Cells past the end of the display are not written and
+// io.EOF is returned. The cursor position is preserved.
+func (l *LCD) WriteAt(p []byte, off int64) (n int, err error) {
+	if off > Lines*Columns {
+		return 0, io.EOF
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.writeAt(p, int(off))
+}
+
+func (l *LCD) restoreCursor(pos cursor) error {
+	if l.pos = pos; pos.Error() != nil {
+		return nil // cursor is past end of display
+	}
+	b := []byte{pLCD, pControl, pos.Byte()}
+	return l.sendOutputReport(b)
+}
+
 func (l *LCD) Read(p []byte) (n int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	for n < len(p) {
 		var b = make([]byte, l.pos.Remaining())
 		var m int
@@ -280,13 +485,68 @@ func (l *LCD) readRaw(p []byte) (n int, err error) {
 		}
 		n += m
 
-		if err = l.AdvanceCursor(m); err != nil {
+		if err = l.advanceCursor(m); err != nil {
 			return
 		}
 	}
 	return
 }
 
+func (l *LCD) readAt(p []byte, off int) (n int, err error) {
+	pos := l.pos
+	defer func() {
+		if e := l.restoreCursor(pos); err == nil {
+			err = e
+		}
+	}()
+
+	if err = l.moveCursor(off/Columns, off%Columns); err != nil {
+		return
+	}
+	if n, err = l.readRaw(p); err == io.EOF && n == len(p) {
+		err = nil // read to end of display
+	}
+	return
+}
+
+// ReadAt reads the cells starting at off into p, numbering cells as
+// WriteAt, as io.ReaderAt. If fewer than len(p) cells remain, io.EOF is
+// returned. The cursor position is preserved.
+func (l *LCD) ReadAt(p []byte, off int64) (n int, err error) {
+	if off > Lines*Columns {
+		return 0, io.EOF
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.readAt(p, int(off))
+}
+
+// Seek moves the cursor to the cell at offset, interpreted according to
+// whence and numbering cells as WriteAt, as io.Seeker. Offsets past the
+// end of the display are clamped to Lines*Columns, where a following
+// Write returns io.EOF unless TextScroll is set.
+func (l *LCD) Seek(offset int64, whence int) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += int64(l.pos)
+	case io.SeekEnd:
+		offset += Lines * Columns
+	default:
+		return 0, errors.New("seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+	if offset > Lines*Columns {
+		offset = Lines * Columns
+	}
+	return offset, l.restoreCursor(cursor(offset))
+}
+
 func (l *LCD) GetInput() (key Key, err error) {
 	prefix := []byte{pKeyInput}
 	b := make([]byte, inputReportDataLen)
@@ -300,9 +560,32 @@ func (l *LCD) GetInput() (key Key, err error) {
 	return
 }
 
+// InjectKey queues key to be returned by GetInput as if it were
+// reported by the display.
+func (l *LCD) InjectKey(key Key) {
+	b := make([]byte, inputReportLen)
+	b[0] = inputReportID
+	copy(b[1:], []byte{pKeyInput, byte(key.Code), byte(key.Event)})
+	b[len(b)-1] = checksum(b)
+
+	l.rmu.Lock()
+	defer l.rmu.Unlock()
+	if len(l.pending) == maxPending {
+		l.pending = l.pending[1:]
+	}
+	l.pending = append(l.pending, b)
+}
+
 func (l *LCD) SetBacklight(state Backlight) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	b := []byte{pBacklight, byte(state)}
-	return l.sendOutputReport(b)
+	if err := l.sendOutputReport(b); err != nil {
+		return err
+	}
+	l.backlight = state
+	return nil
 }
 
 func (l *LCD) Print(a ...interface{}) (int, error) {
@@ -329,4 +612,10 @@ func (l *LCD) Scanln(a ...interface{}) (int, error) {
 	return fmt.Fscanln(l, a...)
 }
 
-var _ io.ReadWriteCloser = (*LCD)(nil)
+var (
+	_ io.ReadWriteCloser = (*LCD)(nil)
+	_ io.ReadWriteSeeker = (*LCD)(nil)
+	_ io.ReaderAt        = (*LCD)(nil)
+	_ io.WriterAt        = (*LCD)(nil)
+	_ Display            = (*LCD)(nil)
+)
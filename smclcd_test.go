@@ -0,0 +1,147 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"io"
+	"testing"
+
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+// newFakeDevice returns a fakehid.Device that returns script in order.
+func newFakeDevice(script ...fakehid.Report) *fakehid.Device {
+	return fakehid.New(ErrTimeout, script...)
+}
+
+var (
+	_ io.ReaderAt = (*LCD)(nil)
+	_ io.WriterAt = (*LCD)(nil)
+	_ io.Seeker   = (*LCD)(nil)
+)
+
+const testFrame = "0123456789abcdefghijklmnopqrstuv"
+
+func TestReadAt(t *testing.T) {
+	l := New(newFakeDevice())
+	if _, err := l.WriteAt([]byte(testFrame), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Seek(5, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		off  int64
+		n    int
+		want string
+		err  error
+	}{
+		{0, 4, "0123", nil},
+		{14, 4, "efgh", nil},
+		{28, 4, "stuv", nil},
+		{30, 4, "uv", io.EOF},
+		{32, 1, "", io.EOF},
+		{33, 1, "", io.EOF},
+	}
+	for _, tt := range tests {
+		p := make([]byte, tt.n)
+		n, err := l.ReadAt(p, tt.off)
+		if string(p[:n]) != tt.want || err != tt.err {
+			t.Errorf("ReadAt([%d]byte, %d) = %q, %v, want %q, %v", tt.n, tt.off, p[:n], err, tt.want, tt.err)
+		}
+	}
+	if pos, _ := l.Seek(0, io.SeekCurrent); pos != 5 {
+		t.Errorf("cursor at %d after ReadAt, want 5", pos)
+	}
+}
+
+func TestWriteAt(t *testing.T) {
+	tests := []struct {
+		off  int64
+		p    string
+		n    int
+		err  error
+		want string
+	}{
+		{0, "ab", 2, nil, "ab" + testFrame[2:]},
+		{14, "XYZ", 3, nil, testFrame[:14] + "XYZ" + testFrame[17:]},
+		{30, "xyz", 2, io.EOF, testFrame[:30] + "xy"},
+		{32, "x", 0, io.EOF, testFrame},
+		{4, "\x01\n\xdf", 3, nil, testFrame[:4] + "\x01\n\xdf" + testFrame[7:]},
+	}
+	for _, tt := range tests {
+		d := newFakeDevice()
+		l := New(d)
+		if _, err := l.WriteAt([]byte(testFrame), 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.Seek(5, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		n, err := l.WriteAt([]byte(tt.p), tt.off)
+		if n != tt.n || err != tt.err {
+			t.Errorf("WriteAt(%q, %d) = %d, %v, want %d, %v", tt.p, tt.off, n, err, tt.n, tt.err)
+		}
+		if got := d.Text(); got != tt.want {
+			t.Errorf("WriteAt(%q, %d) displayed %q, want %q", tt.p, tt.off, got, tt.want)
+		}
+		if pos, _ := l.Seek(0, io.SeekCurrent); pos != 5 {
+			t.Errorf("cursor at %d after WriteAt, want 5", pos)
+		}
+	}
+}
+
+func TestSeek(t *testing.T) {
+	l := New(newFakeDevice())
+	tests := []struct {
+		offset int64
+		whence int
+		want   int64
+		err    bool
+	}{
+		{5, io.SeekStart, 5, false},
+		{3, io.SeekCurrent, 8, false},
+		{-8, io.SeekCurrent, 0, false},
+		{-2, io.SeekEnd, 30, false},
+		{5, io.SeekEnd, 32, false},
+		{100, io.SeekStart, 32, false},
+		{-1, io.SeekStart, 0, true},
+		{0, 3, 0, true},
+	}
+	for _, tt := range tests {
+		pos, err := l.Seek(tt.offset, tt.whence)
+		if (err != nil) != tt.err || (err == nil && pos != tt.want) {
+			t.Errorf("Seek(%d, %d) = %d, %v, want %d", tt.offset, tt.whence, pos, err, tt.want)
+		}
+	}
+
+	// Writes past the clamped offset reach the end of the display.
+	if _, err := l.Seek(0, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := l.Write([]byte("x")); n != 0 || err != io.EOF {
+		t.Errorf("Write() at end = %d, %v, want 0, %v", n, err, io.EOF)
+	}
+}
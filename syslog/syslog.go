@@ -0,0 +1,400 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package syslog displays messages received from syslog.
+//
+// Messages in RFC 5424 and RFC 3164 formats are accepted over datagram
+// sockets. Messages passing a filter on severity, facility, and text are
+// kept in a short history; the newest is shown with its timestamp and
+// position on the first line and its text scrolling on the second. Up
+// and Down browse the history.
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+// DefaultAddr is the address used by the syslog command by default.
+const DefaultAddr = "127.0.0.1:514"
+
+const maxMessageLen = 8192
+
+// Severities, in order of decreasing importance.
+const (
+	SevEmerg = iota
+	SevAlert
+	SevCrit
+	SevErr
+	SevWarning
+	SevNotice
+	SevInfo
+	SevDebug
+)
+
+var severityNames = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console",
+	"solaris-cron", "local0", "local1", "local2", "local3", "local4",
+	"local5", "local6", "local7",
+}
+
+// ParseSeverity returns the severity with the given name or number.
+func ParseSeverity(s string) (int, error) {
+	switch s {
+	case "panic":
+		return SevEmerg, nil
+	case "error":
+		return SevErr, nil
+	case "warn":
+		return SevWarning, nil
+	}
+	return parseName(s, severityNames, "severity")
+}
+
+// ParseFacility returns the facility with the given name or number.
+func ParseFacility(s string) (int, error) {
+	return parseName(s, facilityNames, "facility")
+}
+
+func parseName(s string, names []string, what string) (int, error) {
+	for i, name := range names {
+		if s == name {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(names) {
+		return n, nil
+	}
+	return 0, fmt.Errorf("syslog: unknown %s: %s", what, s)
+}
+
+// Message is a syslog message. Fields not present in the message are
+// empty.
+type Message struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	Text      string
+}
+
+// Parse parses a message in RFC 5424 or RFC 3164 format. If the message
+// does not include a timestamp, the current time is used.
+func Parse(b []byte) (*Message, error) {
+	b = bytes.TrimRight(b, "\r\n\x00")
+	if len(b) < 3 || b[0] != '<' {
+		return nil, errors.New("syslog: missing priority")
+	}
+	i := bytes.IndexByte(b, '>')
+	if i < 2 || i > 4 {
+		return nil, errors.New("syslog: malformed priority")
+	}
+	pri, err := strconv.Atoi(string(b[1:i]))
+	if err != nil || pri > 191 {
+		return nil, errors.New("syslog: malformed priority")
+	}
+	m := &Message{Facility: pri / 8, Severity: pri % 8}
+	s := string(b[i+1:])
+	if strings.HasPrefix(s, "1 ") {
+		err = m.parse5424(s[2:])
+	} else {
+		m.parse3164(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.Timestamp.IsZero() {
+		m.Timestamp = time.Now()
+	}
+	return m, nil
+}
+
+func (m *Message) parse5424(s string) error {
+	var fields [5]string
+	for i := range fields {
+		var ok bool
+		if fields[i], s, ok = cut(s); !ok && i < len(fields)-1 {
+			return errors.New("syslog: truncated header")
+		}
+		if fields[i] == "-" {
+			fields[i] = ""
+		}
+	}
+	if fields[0] != "" {
+		t, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return errors.New("syslog: malformed timestamp")
+		}
+		m.Timestamp = t
+	}
+	m.Hostname, m.AppName, m.ProcID, m.MsgID = fields[1], fields[2], fields[3], fields[4]
+
+	// Skip structured data, which is either "-" or a sequence of
+	// bracketed elements whose values may contain escaped brackets.
+	if strings.HasPrefix(s, "-") {
+		s = s[1:]
+	} else {
+		for strings.HasPrefix(s, "[") {
+			var quoted, escaped bool
+			i := 1
+			for ; i < len(s); i++ {
+				c := s[i]
+				if escaped {
+					escaped = false
+				} else if c == '\\' {
+					escaped = true
+				} else if c == '"' {
+					quoted = !quoted
+				} else if c == ']' && !quoted {
+					break
+				}
+			}
+			if i == len(s) {
+				return errors.New("syslog: malformed structured data")
+			}
+			s = s[i+1:]
+		}
+	}
+	s = strings.TrimPrefix(s, " ")
+	m.Text = strings.TrimPrefix(s, "\ufeff")
+	return nil
+}
+
+// stampLen is the length of an RFC 3164 timestamp.
+var stampLen = len(time.Stamp)
+
+func (m *Message) parse3164(s string) {
+	if len(s) > stampLen && s[stampLen] == ' ' {
+		if t, err := time.ParseInLocation(time.Stamp, s[:stampLen], time.Local); err == nil {
+			now := time.Now()
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.AddDate(0, 1, 0)) {
+				t = t.AddDate(-1, 0, 0) // sent last year
+			}
+			m.Timestamp = t
+			s = s[stampLen+1:]
+
+			// The hostname is often omitted by local senders; assume
+			// it is present unless the next field looks like a tag.
+			if host, rest, ok := cut(s); ok && !isTag(host) {
+				m.Hostname, s = host, rest
+			}
+		}
+	}
+
+	if tag, rest, ok := cut(s); ok && isTag(tag) {
+		tag = strings.TrimSuffix(tag, ":")
+		if i := strings.IndexByte(tag, '['); i >= 0 && strings.HasSuffix(tag, "]") {
+			m.ProcID = tag[i+1 : len(tag)-1]
+			tag = tag[:i]
+		}
+		m.AppName, s = tag, rest
+	}
+	m.Text = s
+}
+
+func isTag(s string) bool {
+	return strings.HasSuffix(s, ":") || strings.HasSuffix(s, "]")
+}
+
+// cut slices s around the first space.
+func cut(s string) (before, after string, found bool) {
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, "", false
+}
+
+// Server receives syslog messages and displays those matching Severity,
+// Facilities, and Pattern. Severity is the least important severity
+// shown; if Facilities is non-empty, only the facilities it contains
+// are shown. If Pattern is non-nil, only messages whose text matches are
+// shown. At most History messages are kept; the newest message is always
+// kept, even if History is less than one.
+//
+// Errors updating the display are logged to ErrorLog, or if nil, the
+// standard logger.
+type Server struct {
+	Severity   int
+	Facilities map[int]bool
+	Pattern    *regexp.Regexp
+	History    int
+
+	LCD      *smclcd.LCD
+	ErrorLog *log.Logger
+
+	mu       sync.Mutex
+	messages []*Message // newest first
+	cur      int
+	failed   bool
+	once     sync.Once
+	marquee  *smclcd.Marquee
+}
+
+func NewServer(l *smclcd.LCD) *Server {
+	return &Server{
+		Severity: SevErr,
+		History:  10,
+		LCD:      l,
+	}
+}
+
+// Serve receives messages on c until an error occurs. Malformed messages
+// are ignored.
+func (s *Server) Serve(c net.PacketConn) error {
+	var err error
+	s.once.Do(func() {
+		s.marquee = s.LCD.NewMarquee(smclcd.Region{Y: 1, Width: smclcd.Columns})
+		if err = s.marquee.Start(); err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.report(s.draw())
+		s.mu.Unlock()
+		go s.pumpKeys()
+	})
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, maxMessageLen)
+	for {
+		n, _, err := c.ReadFrom(b)
+		if err != nil {
+			return err
+		}
+		m, err := Parse(b[:n])
+		if err != nil || !s.match(m) {
+			continue
+		}
+
+		s.mu.Lock()
+		s.messages = append([]*Message{m}, s.messages...)
+		if n := s.history(); len(s.messages) > n {
+			s.messages = s.messages[:n]
+		}
+		s.cur = 0
+		s.report(s.draw())
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) history() int {
+	if s.History < 1 {
+		return 1
+	}
+	return s.History
+}
+
+func (s *Server) match(m *Message) bool {
+	if m.Severity > s.Severity {
+		return false
+	}
+	if len(s.Facilities) > 0 && !s.Facilities[m.Facility] {
+		return false
+	}
+	return s.Pattern == nil || s.Pattern.MatchString(m.Text)
+}
+
+func (s *Server) pumpKeys() {
+	r := smclcd.NewKeyFilter(s.LCD)
+	for {
+		key, err := r.GetInput()
+		if err != nil {
+			return
+		}
+		if key.Event != smclcd.KeyPress {
+			continue
+		}
+		var n int
+		switch key.Code {
+		case smclcd.KeyUp:
+			n = -1
+		case smclcd.KeyDown:
+			n = 1
+		default:
+			continue
+		}
+
+		s.mu.Lock()
+		if len(s.messages) > 0 {
+			s.cur = (s.cur + n + len(s.messages)) % len(s.messages)
+			s.report(s.draw())
+		}
+		s.mu.Unlock()
+	}
+}
+
+// report logs err, unless the previous update also failed. The caller
+// must hold s.mu.
+func (s *Server) report(err error) {
+	if err != nil && !s.failed {
+		s.logf("syslog: %v", err)
+	}
+	s.failed = err != nil
+}
+
+func (s *Server) logf(format string, v ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+// draw shows the current message. The caller must hold s.mu.
+func (s *Server) draw() error {
+	if len(s.messages) == 0 {
+		s.marquee.SetText("")
+		return s.LCD.SetLine(0, "No messages", smclcd.AlignLeft)
+	}
+
+	m := s.messages[s.cur]
+	text := m.Text
+	if m.AppName != "" {
+		text = m.AppName + ": " + text
+	}
+	s.marquee.SetText(strings.Join(strings.Fields(text), " "))
+
+	pos := fmt.Sprintf("%d/%d", s.cur+1, len(s.messages))
+	stamp := m.Timestamp.Local().Format("15:04:05")
+	return s.LCD.SetLine(0, fmt.Sprintf("%-*s%s", smclcd.Columns-len(pos), stamp, pos), smclcd.AlignLeft)
+}
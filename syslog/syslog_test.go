@@ -0,0 +1,268 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Message
+	}{
+		{
+			`<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed for lonvick on /dev/pts/8`,
+			Message{Facility: 4, Severity: SevCrit, Hostname: "mymachine.example.com", AppName: "su",
+				MsgID: "ID47", Text: "'su root' failed for lonvick on /dev/pts/8"},
+		},
+		{
+			"<165>1 2003-08-24T05:14:15.000003-07:00 192.0.2.1 myproc 8710 - - %% It's time to make the do-nuts.",
+			Message{Facility: 20, Severity: SevNotice, Hostname: "192.0.2.1", AppName: "myproc",
+				ProcID: "8710", Text: "%% It's time to make the do-nuts."},
+		},
+		{
+			`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="App\]lication"][x@1 a="]"] ` + "\ufeff" + `An application event`,
+			Message{Facility: 20, Severity: SevNotice, Hostname: "mymachine.example.com", AppName: "evntslog",
+				MsgID: "ID47", Text: "An application event"},
+		},
+		{
+			"<11>1 - - - - - -\n",
+			Message{Facility: 1, Severity: SevErr},
+		},
+		{
+			"<34>Oct 11 22:14:15 mymachine su: 'su root' failed",
+			Message{Facility: 4, Severity: SevCrit, Hostname: "mymachine", AppName: "su",
+				Text: "'su root' failed"},
+		},
+		{
+			"<13>Oct  1 01:02:03 sshd[1234]: Accepted publickey",
+			Message{Facility: 1, Severity: SevNotice, AppName: "sshd", ProcID: "1234",
+				Text: "Accepted publickey"},
+		},
+		{
+			"<0>kernel panic",
+			Message{Facility: 0, Severity: SevEmerg, Text: "kernel panic"},
+		},
+	}
+	for _, tt := range tests {
+		m, err := Parse([]byte(tt.in))
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if m.Timestamp.IsZero() {
+			t.Errorf("Parse(%q): zero timestamp", tt.in)
+		}
+		m.Timestamp = time.Time{}
+		if *m != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, *m, tt.want)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	m, err := Parse([]byte("<165>1 2003-08-24T05:14:15.000003-07:00 host app - - - text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2003, 8, 24, 12, 14, 15, 3000, time.UTC)
+	if !m.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", m.Timestamp, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"no priority",
+		"<>1 - - - - - -",
+		"<12345>text",
+		"<192>text",
+		"<x>text",
+		"<34>1 2003-10-11T22:14:15Z host",
+		"<34>1 yesterday host app - - - text",
+		"<34>1 - host app - - [unterminated text",
+	}
+	for _, s := range tests {
+		if m, err := Parse([]byte(s)); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", s, m)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+		ok   bool
+	}{
+		{"emerg", SevEmerg, true},
+		{"panic", SevEmerg, true},
+		{"err", SevErr, true},
+		{"error", SevErr, true},
+		{"warn", SevWarning, true},
+		{"7", SevDebug, true},
+		{"8", 0, false},
+		{"loud", 0, false},
+	}
+	for _, tt := range tests {
+		n, err := ParseSeverity(tt.s)
+		if (err == nil) != tt.ok || n != tt.want {
+			t.Errorf("ParseSeverity(%q) = %d, %v", tt.s, n, err)
+		}
+	}
+}
+
+func TestParseFacility(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+		ok   bool
+	}{
+		{"kern", 0, true},
+		{"auth", 4, true},
+		{"local7", 23, true},
+		{"16", 16, true},
+		{"24", 0, false},
+		{"-1", 0, false},
+		{"nope", 0, false},
+	}
+	for _, tt := range tests {
+		n, err := ParseFacility(tt.s)
+		if (err == nil) != tt.ok || n != tt.want {
+			t.Errorf("ParseFacility(%q) = %d, %v", tt.s, n, err)
+		}
+	}
+}
+
+// serve starts s on a loopback socket and returns a function sending msg
+// to it, which waits for the message to be received.
+func serve(t *testing.T, s *Server) func(msg string) {
+	t.Helper()
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	go s.Serve(c)
+
+	w, err := net.Dial("udp", c.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return func(msg string) {
+		t.Helper()
+		if _, err := fmt.Fprintf(w, "<11>1 - - - - - - %s", msg); err != nil {
+			t.Fatal(err)
+		}
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+			s.mu.Lock()
+			ok := len(s.messages) > 0 && s.messages[0].Text == msg
+			s.mu.Unlock()
+			if ok {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("%q not received", msg)
+	}
+}
+
+func TestServeHistory(t *testing.T) {
+	tests := []struct {
+		history int
+		want    int
+	}{
+		{-1, 1},
+		{0, 1},
+		{2, 2},
+		{10, 3},
+	}
+	for _, tt := range tests {
+		d := fakehid.New(smclcd.ErrTimeout)
+		defer d.Close()
+		s := NewServer(smclcd.New(d))
+		s.History = tt.history
+		send := serve(t, s)
+		for _, msg := range []string{"one", "two", "three"} {
+			send(msg)
+		}
+		s.mu.Lock()
+		if n := len(s.messages); n != tt.want {
+			t.Errorf("History %d: kept %d messages, want %d", tt.history, n, tt.want)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// failingDevice fails output reports once fail is set.
+type failingDevice struct {
+	*fakehid.Device
+	mu   sync.Mutex
+	fail bool
+}
+
+func (d *failingDevice) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.fail {
+		return 0, errors.New("write failed")
+	}
+	return d.Device.Write(p)
+}
+
+func TestServeErrors(t *testing.T) {
+	d := &failingDevice{Device: fakehid.New(smclcd.ErrTimeout)}
+	defer d.Close()
+	var buf bytes.Buffer
+	s := NewServer(smclcd.New(d))
+	s.ErrorLog = log.New(&buf, "", 0)
+	send := serve(t, s)
+	send("ok")
+
+	d.mu.Lock()
+	d.fail = true
+	d.mu.Unlock()
+	send("first")
+	send("second")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := strings.Count(buf.String(), "syslog: "); n != 1 {
+		t.Errorf("logged %d errors, want 1:\n%s", n, buf.String())
+	}
+}
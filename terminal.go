@@ -0,0 +1,256 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+const (
+	termGround = iota
+	termEscape
+	termEscapeIntermediate
+	termCSI
+	termString
+	termStringEscape
+)
+
+// maxParamLen limits the length of CSI parameters; further parameter
+// bytes are discarded.
+const maxParamLen = 32
+
+// maxParam limits the value of a CSI parameter.
+const maxParam = 9999
+
+// Terminal interprets a subset of VT100 escape sequences written to the
+// display. Cursor movement (CUU, CUD, CUF, CUB, CHA, VPA, CUP), erasing
+// (ED, EL), cursor visibility (DECTCEM), and cursor save/restore are
+// supported. All other sequences, including SGR, are discarded. Control
+// characters are interpreted as if TextControl were set, including
+// within escape sequences; CAN and SUB cancel the sequence.
+type Terminal struct {
+	Cursor Cursor // cursor shown by DECTCEM; defaults to CursorUnderline
+
+	l      *LCD
+	state  int
+	params []byte
+	saved  cursor
+}
+
+func NewTerminal(l *LCD) *Terminal {
+	return &Terminal{Cursor: CursorUnderline, l: l}
+}
+
+func (t *Terminal) Write(p []byte) (n int, err error) {
+	t.l.mu.Lock()
+	defer t.l.mu.Unlock()
+
+	mode := t.l.mode
+	t.l.mode |= TextControl
+	defer func() { t.l.mode = mode }()
+
+	for n < len(p) {
+		var m = 1
+		inString := t.state == termString || t.state == termStringEscape
+		switch c := p[n]; {
+		case t.state == termGround:
+			if c == 0x1b {
+				t.state = termEscape
+				break
+			}
+			m = bytes.IndexByte(p[n:], 0x1b)
+			if m < 0 {
+				m = len(p[n:])
+			}
+			b := bytes.ReplaceAll(p[n:n+m], []byte{0x7f}, nil)
+			if _, err = t.l.writeText(b); err != nil {
+				return
+			}
+		case c == 0x18 || c == 0x1a: // CAN, SUB
+			t.state = termGround
+		case c == 0x1b && !inString:
+			t.state = termEscape
+		case c < 0x20 && !inString:
+			_, err = t.l.writeText([]byte{c})
+		case c == 0x7f:
+			// DEL is ignored.
+		default:
+			err = t.sequence(c)
+		}
+		if err != nil {
+			return
+		}
+		n += m
+	}
+	return
+}
+
+// sequence interprets c as part of an escape sequence.
+func (t *Terminal) sequence(c byte) (err error) {
+	switch t.state {
+	case termEscape:
+		t.state = termGround
+		switch c {
+		case '[':
+			t.state = termCSI
+			t.params = t.params[:0]
+		case ']', 'P', 'X', '^', '_':
+			t.state = termString
+		case '7':
+			t.saved = t.l.pos
+		case '8':
+			err = t.l.restoreCursor(t.saved)
+		case 'c':
+			err = t.l.clear()
+		default:
+			if c >= 0x20 && c <= 0x2f {
+				t.state = termEscapeIntermediate
+			}
+		}
+	case termEscapeIntermediate:
+		// Discard designations such as ESC ( B through the final
+		// byte.
+		if c < 0x20 || c > 0x2f {
+			t.state = termGround
+		}
+	case termCSI:
+		if c >= 0x40 && c <= 0x7e {
+			t.state = termGround
+			err = t.dispatch(c)
+		} else if len(t.params) < maxParamLen {
+			t.params = append(t.params, c)
+		}
+	case termString:
+		switch c {
+		case 0x07:
+			t.state = termGround
+		case 0x1b:
+			t.state = termStringEscape
+		}
+	case termStringEscape:
+		t.state = termString
+		if c == '\\' {
+			t.state = termGround
+		}
+	}
+	return
+}
+
+func (t *Terminal) param(i, def int) int {
+	params := bytes.Split(t.params, []byte(";"))
+	if i < len(params) {
+		if v, err := strconv.Atoi(string(params[i])); err == nil && v > 0 {
+			return clamp(v, 1, maxParam)
+		}
+	}
+	return def
+}
+
+func (t *Terminal) dispatch(c byte) error {
+	y, x := int(t.l.pos)/Columns, int(t.l.pos)%Columns
+	if t.l.pos.Error() == io.EOF {
+		y, x = Lines-1, Columns-1
+	}
+	if bytes.HasPrefix(t.params, []byte("?")) {
+		if string(t.params) == "?25" {
+			switch c {
+			case 'h':
+				return t.l.setCursor(t.Cursor)
+			case 'l':
+				return t.l.setCursor(CursorOff)
+			}
+		}
+		return nil // ignore private sequences
+	}
+	switch c {
+	case 'A':
+		return t.move(y-t.param(0, 1), x)
+	case 'B':
+		return t.move(y+t.param(0, 1), x)
+	case 'C':
+		return t.move(y, x+t.param(0, 1))
+	case 'D':
+		return t.move(y, x-t.param(0, 1))
+	case 'G':
+		return t.move(y, t.param(0, 1)-1)
+	case 'd':
+		return t.move(t.param(0, 1)-1, x)
+	case 'H', 'f':
+		return t.move(t.param(0, 1)-1, t.param(1, 1)-1)
+	case 'J':
+		off := y*Columns + x
+		switch t.param(0, 0) {
+		case 0:
+			return t.erase(off, Lines*Columns)
+		case 1:
+			return t.erase(0, off+1)
+		default:
+			return t.erase(0, Lines*Columns)
+		}
+	case 'K':
+		off := y * Columns
+		switch t.param(0, 0) {
+		case 0:
+			return t.erase(off+x, off+Columns)
+		case 1:
+			return t.erase(off, off+x+1)
+		default:
+			return t.erase(off, off+Columns)
+		}
+	case 's':
+		t.saved = t.l.pos
+	case 'u':
+		return t.l.restoreCursor(t.saved)
+	}
+	return nil // ignore unsupported sequences
+}
+
+func (t *Terminal) move(y, x int) error {
+	y = clamp(y, 0, Lines-1)
+	x = clamp(x, 0, Columns-1)
+	return t.l.moveCursor(y, x)
+}
+
+func (t *Terminal) erase(start, end int) (err error) {
+	if start < end {
+		b := bytes.Repeat([]byte(" "), end-start)
+		_, err = t.l.writeAt(b, start)
+	}
+	return
+}
+
+func clamp(v, lo, hi int) int {
+	switch {
+	case v < lo:
+		return lo
+	case v > hi:
+		return hi
+	default:
+		return v
+	}
+}
+
+var _ io.Writer = (*Terminal)(nil)
@@ -0,0 +1,136 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTerminalDiscard(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Hi\x1b[1;31m!", "Hi!"},
+		{"Hi\x1b(B\x1b[m!", "Hi!"},
+		{"Hi\x1b#8!", "Hi!"},
+		{"Hi\x1b ( B!", "Hi!"},
+		{"Hi\x1b]0;title\x07!", "Hi!"},
+		{"Hi\x1bP1$r\x1b\\!", "Hi!"},
+		{"Hi\x1b[1\x18!", "Hi!"},
+		{"Hi\x1b]0;title\x1a!", "Hi!"},
+		{"Hi\x1b(\x18!", "Hi!"},
+		{"Hi\x1b[\x1b[m!", "Hi!"},
+		{"Hi\x1b\x1b[m!", "Hi!"},
+		{"Hi\x1b[1\x7fm!", "Hi!"},
+		{"Hi\x1b[" + strings.Repeat("1;", 1000) + "m!", "Hi!"},
+	}
+	for _, tt := range tests {
+		d := newFakeDevice()
+		if _, err := NewTerminal(New(d)).Write([]byte(tt.in)); err != nil {
+			t.Fatal(err)
+		}
+		if s := string(d.Written()); s != tt.want {
+			t.Errorf("Write(%q) displayed %q, want %q", tt.in, s, tt.want)
+		}
+	}
+}
+
+// lines returns the text of the display with trailing spaces and blank
+// lines removed.
+func lines(text string) string {
+	var s []string
+	for y := 0; y < Lines; y++ {
+		s = append(s, strings.TrimRight(text[y*Columns:(y+1)*Columns], " "))
+	}
+	return strings.TrimRight(strings.Join(s, "\n"), "\n")
+}
+
+func TestTerminal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"\x1b[2;5Hx", "\n    x"},
+		{"\x1b[1;99Hx", "               x"},
+		{"abc\x1b[\r2Cx", "abx"},
+		{"ab\x1b[1\nBx", "ab\nx"},
+		{"abc\x1b[\b\bDx", "xbc"},
+		{"\x1b[99999999999999999999Cx", " x"},
+		{"\x1b[9223372036854775807Cx", "               x"},
+		{"\x1b[2;9223372036854775807Hx", "\n               x"},
+		{"abcdef\x1b[1;3H\x1b[K", "ab"},
+		{"abcdef\x1b[1;3H\x1b[1K", "   def"},
+		{"abc\ndef\x1b[1;2H\x1b[J", "a"},
+		{"abc\ndef\x1b[2;2H\x1b[1J", "\n  f"},
+		{"abc\ndef\x1b[2J", ""},
+		{"ab\x1b7cd\x1b8x", "abxd"},
+		{"ab\x1b[scd\x1b[ux", "abxd"},
+		{"ab\x1b[s\x1b[" + strings.Repeat("1", 100) + "C\x1b[ux", "abx"},
+		{"abc\x1bcx", "x"},
+	}
+	for _, tt := range tests {
+		d := newFakeDevice()
+		if _, err := NewTerminal(New(d)).Write([]byte(tt.in)); err != nil {
+			t.Fatal(err)
+		}
+		if s := lines(d.Text()); s != tt.want {
+			t.Errorf("Write(%q) displayed %q, want %q", tt.in, s, tt.want)
+		}
+	}
+}
+
+func TestTerminalCursor(t *testing.T) {
+	d := newFakeDevice()
+	term := NewTerminal(New(d))
+	term.Cursor = CursorBlock
+	tests := []struct {
+		in   string
+		want Cursor
+	}{
+		{"\x1b[?25h", CursorBlock},
+		{"\x1b[?25l", CursorOff},
+		{"\x1b[?25\x18h", CursorOff},
+		{"\x1b[?2\r5h", CursorBlock},
+	}
+	for _, tt := range tests {
+		if _, err := term.Write([]byte(tt.in)); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, state := d.Cursor(); state != byte(tt.want) {
+			t.Errorf("Write(%q) cursor = %d, want %d", tt.in, state, tt.want)
+		}
+	}
+}
+
+func TestTerminalParamLen(t *testing.T) {
+	term := NewTerminal(New(newFakeDevice()))
+	if _, err := term.Write([]byte("\x1b[" + strings.Repeat("1;", 1000))); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(term.params); n > maxParamLen {
+		t.Errorf("%d parameter bytes held, want at most %d", n, maxParamLen)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/sstallion/go-tools/util"
+)
+
+// TextMode controls how Write interprets text. The zero value preserves
+// the default behavior, which only handles newlines.
+type TextMode byte
+
+const (
+	TextWrap    TextMode = 1 << iota // Wrap lines at word boundaries
+	TextControl                      // Honor \r, \t, \f, and \b
+	TextScroll                       // Scroll up when writing past last line
+)
+
+// TabWidth is the distance between tab stops when TextControl is set.
+const TabWidth = 4
+
+func (l *LCD) SetTextMode(mode TextMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mode = mode
+}
+
+func (l *LCD) writeText(p []byte) (n int, err error) {
+	var wrapped bool
+	for n < len(p) {
+		var m = 1
+		switch c := p[n]; {
+		case c == '\n':
+			if !wrapped {
+				err = l.putText(bytes.Repeat([]byte(" "), l.pos.Remaining()))
+			}
+			wrapped = false
+		case c < ' ' && l.mode&TextControl != 0:
+			err = l.control(c)
+			wrapped = false
+		case c == ' ':
+			if !wrapped || l.mode&TextWrap == 0 {
+				err = l.putText(p[n : n+1])
+				wrapped = l.pos.Remaining() == Columns
+			}
+		default:
+			for n+m < len(p) && p[n+m] > ' ' {
+				m++
+			}
+			err = l.putWord(p[n : n+m])
+			wrapped = l.pos.Remaining() == Columns
+		}
+		if err != nil {
+			return
+		}
+		n += m
+	}
+	return
+}
+
+func (l *LCD) putWord(p []byte) error {
+	remaining := l.pos.Remaining()
+	if l.mode&TextWrap != 0 && len(p) > remaining && len(p) <= Columns &&
+		remaining < Columns {
+		b := bytes.Repeat([]byte(" "), remaining)
+		if err := l.putText(b); err != nil {
+			return err
+		}
+	}
+	return l.putText(p)
+}
+
+func (l *LCD) putText(p []byte) (err error) {
+	for len(p) > 0 {
+		if err = l.pos.Error(); err == io.EOF && l.mode&TextScroll != 0 {
+			err = l.scroll()
+		}
+		if err != nil {
+			return
+		}
+
+		n := util.Min(len(p), l.pos.Remaining())
+		if _, err = l.writeRaw(p[:n]); err != nil && err != io.EOF {
+			return
+		}
+		p = p[n:]
+	}
+	return nil
+}
+
+func (l *LCD) control(c byte) error {
+	y, x := int(l.pos)/Columns, int(l.pos)%Columns
+	if l.pos.Error() == io.EOF {
+		y, x = Lines-1, Columns
+	}
+	switch c {
+	case '\r':
+		return l.moveCursor(y, 0)
+	case '\t':
+		b := bytes.Repeat([]byte(" "), TabWidth-x%TabWidth)
+		return l.putText(b)
+	case '\f':
+		return l.clear()
+	case '\b':
+		if x > 0 {
+			return l.moveCursor(y, x-1)
+		}
+	}
+	return nil // ignore unsupported characters
+}
+
+func (l *LCD) scroll() (err error) {
+	b := make([]byte, Columns)
+	for y := 1; y < Lines; y++ {
+		if err = l.moveCursor(y, 0); err != nil {
+			return
+		}
+		if _, err = l.readRaw(b); err != nil && err != io.EOF {
+			return
+		}
+		if err = l.moveCursor(y-1, 0); err != nil {
+			return
+		}
+		if _, err = l.writeCells(b); err != nil {
+			return
+		}
+	}
+	b = bytes.Repeat([]byte(" "), Columns)
+	if _, err = l.writeRaw(b); err != nil && err != io.EOF {
+		return
+	}
+	return l.moveCursor(Lines-1, 0)
+}
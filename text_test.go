@@ -0,0 +1,72 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import "testing"
+
+func TestTextMode(t *testing.T) {
+	tests := []struct {
+		mode TextMode
+		in   string
+		want string
+	}{
+		// Without modes, text runs on and control characters are shown.
+		{0, "hello world, how are you", "hello world, how are you        "},
+		{0, "a\tb\rc", "a?b?c                           "},
+		{0, "ab\ncd", "ab              cd              "},
+
+		// TextWrap breaks lines between words.
+		{TextWrap, "good morning, how are you", "good morning,   how are you     "},
+		{TextWrap, "one two three four five", "one two three   four five       "},
+		{TextWrap, "abcdefghijklmnopqrst", "abcdefghijklmnopqrst            "},
+		{TextWrap, "sixteen letters! next", "sixteen letters!next            "},
+		{TextWrap, "ab\ncd ef", "ab              cd ef           "},
+
+		// TextControl honors \r, \t, \f, and \b.
+		{TextControl, "abc\rd", "dbc                             "},
+		{TextControl, "a\tb\tc", "a   b   c                       "},
+		{TextControl, "abcd\te", "abcd    e                       "},
+		{TextControl, "abc\bd", "abd                             "},
+		{TextControl, "\bab", "ab                              "},
+		{TextControl, "abc\fd", "d                               "},
+		{TextControl, "a\x07b", "ab                              "},
+		{TextControl, "ab\ncd\rx", "ab              xd              "},
+
+		// TextScroll moves text up when writing past the last line.
+		{TextScroll, "0123456789abcdefghijklmnopqrstuvwxyz", "ghijklmnopqrstuvwxyz            "},
+		{TextScroll, "one\ntwo\nthree", "two             three           "},
+		{TextScroll | TextWrap, "one two three four five six seven", "four five six   seven           "},
+	}
+	for _, tt := range tests {
+		d := newFakeDevice()
+		l := New(d)
+		l.SetTextMode(tt.mode)
+		if _, err := l.Write([]byte(tt.in)); err != nil && tt.mode&TextScroll != 0 {
+			t.Fatalf("mode %#x: Write(%q): %v", tt.mode, tt.in, err)
+		}
+		if got := d.Text(); got != tt.want {
+			t.Errorf("mode %#x: Write(%q) displayed %q, want %q", tt.mode, tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"time"
+)
+
+type Transition byte
+
+//go:generate stringer -type Transition -trimprefix=Transition
+
+const (
+	TransitionNone Transition = iota
+	TransitionSlideLeft
+	TransitionSlideRight
+	TransitionWipe
+	TransitionTypewriter
+	TransitionScrollUp
+)
+
+// Steps returns the intermediate frames used to transition from prev to
+// f, ending with f.
+func (t Transition) Steps(prev, f *Frame) (steps []Frame) {
+	switch t {
+	case TransitionSlideLeft, TransitionSlideRight, TransitionWipe:
+		for k := 1; k <= Columns; k++ {
+			var s Frame
+			for y := 0; y < Lines; y++ {
+				a, b, line := prev.Line(y), f.Line(y), s.Line(y)
+				switch t {
+				case TransitionSlideLeft:
+					copy(line, a[k:])
+					copy(line[Columns-k:], b[:k])
+				case TransitionSlideRight:
+					copy(line, b[Columns-k:])
+					copy(line[k:], a[:Columns-k])
+				default:
+					copy(line, b[:k])
+					copy(line[k:], a[k:])
+				}
+			}
+			steps = append(steps, s)
+		}
+	case TransitionTypewriter:
+		s := NewFrame("")
+		steps = append(steps, s)
+		for i, c := range f {
+			if c != ' ' {
+				s[i] = c
+				steps = append(steps, s)
+			}
+		}
+	case TransitionScrollUp:
+		for k := 1; k < Lines; k++ {
+			var s Frame
+			copy(s[:], prev[k*Columns:])
+			copy(s[(Lines-k)*Columns:], f[:])
+			steps = append(steps, s)
+		}
+	}
+	if n := len(steps); n == 0 || steps[n-1] != *f {
+		steps = append(steps, *f)
+	}
+	return
+}
+
+// DrawTransition replaces the contents of the display, held by prev,
+// with f using transition t. Each intermediate frame is displayed for
+// step. If prev is nil, f is drawn without a transition.
+func (l *LCD) DrawTransition(prev, f *Frame, t Transition, step time.Duration) (err error) {
+	if prev == nil {
+		return l.DrawFrame(nil, f)
+	}
+	cur := *prev
+	for i, s := range t.Steps(prev, f) {
+		if i > 0 {
+			time.Sleep(step)
+		}
+		if err = l.DrawFrame(&cur, &s); err != nil {
+			return
+		}
+		cur = s
+	}
+	return
+}
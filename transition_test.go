@@ -0,0 +1,113 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package smclcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSteps(t *testing.T) {
+	prev := NewFrame("0123456789abcdef\nABCDEFGHIJKLMNOP")
+	f := NewFrame("ab c\nd")
+	tests := []struct {
+		t     Transition
+		n     int
+		first string
+	}{
+		{TransitionNone, 1, "ab c            d               "},
+		{TransitionSlideLeft, Columns, "123456789abcdefaBCDEFGHIJKLMNOPd"},
+		{TransitionSlideRight, Columns, " 0123456789abcde ABCDEFGHIJKLMNO"},
+		{TransitionWipe, Columns, "a123456789abcdefdBCDEFGHIJKLMNOP"},
+		{TransitionTypewriter, 5, "                                "},
+		{TransitionScrollUp, Lines, "ABCDEFGHIJKLMNOPab c            "},
+	}
+	for _, tt := range tests {
+		steps := tt.t.Steps(&prev, &f)
+		if len(steps) != tt.n {
+			t.Errorf("%v: %d steps, want %d", tt.t, len(steps), tt.n)
+			continue
+		}
+		if s := string(steps[0][:]); s != tt.first {
+			t.Errorf("%v: first step = %q, want %q", tt.t, s, tt.first)
+		}
+		if steps[len(steps)-1] != f {
+			t.Errorf("%v: last step = %q, want %q", tt.t, steps[len(steps)-1][:], f[:])
+		}
+	}
+}
+
+func TestStepsTypewriter(t *testing.T) {
+	prev := NewFrame("old")
+	f := NewFrame("ab c")
+	want := []string{"", "a", "ab", "ab c"}
+	steps := TransitionTypewriter.Steps(&prev, &f)
+	if len(steps) != len(want) {
+		t.Fatalf("%d steps, want %d", len(steps), len(want))
+	}
+	for i, s := range want {
+		if steps[i] != NewFrame(s) {
+			t.Errorf("step %d = %q, want %q", i, steps[i][:], s)
+		}
+	}
+
+	// A blank frame is drawn in a single step.
+	blank := NewFrame("")
+	if steps := TransitionTypewriter.Steps(&prev, &blank); len(steps) != 1 || steps[0] != blank {
+		t.Errorf("blank steps = %q, want blank frame", steps)
+	}
+}
+
+func TestDrawTransition(t *testing.T) {
+	prev := NewFrame("0123456789abcdef\nABCDEFGHIJKLMNOP")
+	f := NewFrame("hello\nworld")
+	for tr := TransitionNone; tr <= TransitionScrollUp; tr++ {
+		d := newFakeDevice()
+		l := New(d)
+		if err := l.DrawFrame(nil, &prev); err != nil {
+			t.Fatal(err)
+		}
+		const step = time.Millisecond
+		start := time.Now()
+		if err := l.DrawTransition(&prev, &f, tr, step); err != nil {
+			t.Fatal(err)
+		}
+		elapsed := time.Since(start)
+		if text := d.Text(); text != string(f[:]) {
+			t.Errorf("%v: display = %q, want %q", tr, text, f[:])
+		}
+		if min := time.Duration(len(tr.Steps(&prev, &f))-1) * step; elapsed < min {
+			t.Errorf("%v: took %v, want at least %v", tr, elapsed, min)
+		}
+	}
+
+	// Without a previous frame, f is drawn immediately.
+	d := newFakeDevice()
+	if err := New(d).DrawTransition(nil, &f, TransitionSlideLeft, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if text := d.Text(); text != string(f[:]) {
+		t.Errorf("display = %q, want %q", text, f[:])
+	}
+}
@@ -0,0 +1,536 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+// Package web serves the display over HTTP.
+//
+// Endpoints are rooted at /api and are described by the OpenAPI document
+// served at /api/openapi.json. Requests accept either JSON or plain text
+// bodies; key events are streamed using Server-Sent Events. Requests
+// other than GET and HEAD sent by browsers from another origin are
+// rejected, as plain text bodies may be posted cross-origin without a
+// CORS preflight.
+//
+// If enabled, a virtual front panel is served at the root. The panel
+// mirrors the display, including custom glyphs, the cursor, and the
+// backlight, and its buttons inject key presses as if the physical keys
+// were pressed.
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+)
+
+//go:embed openapi.json
+var openapi []byte
+
+//go:embed panel.html
+var panel []byte
+
+// maxKeys is the number of key events buffered for each stream; further
+// events are dropped until the stream catches up.
+const maxKeys = 16
+
+// keepAlive is the interval between comments sent to idle streams.
+const keepAlive = 30 * time.Second
+
+// pressTime is how long injected keys are held down.
+const pressTime = 100 * time.Millisecond
+
+// Server is an http.Handler serving the display. If Panel is set, the
+// virtual front panel and the endpoints it uses are enabled.
+type Server struct {
+	LCD   *smclcd.LCD
+	Panel bool
+
+	mux *http.ServeMux
+
+	mu    sync.Mutex
+	subs  map[chan smclcd.Key]struct{}
+	timer *time.Timer
+	saved smclcd.Frame
+	shown smclcd.Frame
+}
+
+func NewServer(l *smclcd.LCD) *Server {
+	s := &Server{
+		LCD:  l,
+		mux:  http.NewServeMux(),
+		subs: make(map[chan smclcd.Key]struct{}),
+	}
+	s.mux.HandleFunc("/api/screen", s.handleScreen)
+	s.mux.HandleFunc("/api/backlight", s.handleBacklight)
+	s.mux.HandleFunc("/api/cursor", s.handleCursor)
+	s.mux.HandleFunc("/api/message", s.handleMessage)
+	s.mux.HandleFunc("/api/keys", s.handleKeys)
+	s.mux.HandleFunc("/api/version", s.handleVersion)
+	s.mux.HandleFunc("/api/openapi.json", s.handleOpenAPI)
+	s.mux.HandleFunc("/api/state", s.handleState)
+	s.mux.HandleFunc("/api/inject", s.handleInject)
+	s.mux.HandleFunc("/", s.handlePanel)
+	go s.pumpKeys()
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && !sameOrigin(r) {
+		httpError(w, errors.New("cross-origin request"), http.StatusForbidden)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// sameOrigin reports whether r was sent from a page served by this host
+// or by a client other than a browser, which does not set Origin.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+type screenJSON struct {
+	Lines []string `json:"lines"`
+}
+
+type stateJSON struct {
+	State  string `json:"state"`
+	Line   *int   `json:"line,omitempty"`
+	Column *int   `json:"column,omitempty"`
+}
+
+type messageJSON struct {
+	Lines    []string `json:"lines"`
+	Duration string   `json:"duration"`
+}
+
+type keyJSON struct {
+	Code  string `json:"code"`
+	Event string `json:"event,omitempty"`
+}
+
+func (s *Server) handleScreen(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		f, err := s.LCD.ReadFrame()
+		if err != nil {
+			httpError(w, err, http.StatusInternalServerError)
+			return
+		}
+		text := f.Text()
+		if wantText(r) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprintln(w, text)
+			return
+		}
+		writeJSON(w, screenJSON{Lines: strings.Split(text, "\n")})
+	case http.MethodPut:
+		var v screenJSON
+		text, err := readBody(r, &v)
+		if err != nil {
+			httpError(w, err, http.StatusBadRequest)
+			return
+		}
+		if text == "" {
+			text = strings.Join(v.Lines, "\n")
+		}
+		s.mu.Lock()
+		s.cancelMessage()
+		err = s.draw(text)
+		s.mu.Unlock()
+		if err != nil {
+			httpError(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut)
+	}
+}
+
+func (s *Server) draw(text string) (err error) {
+	var f smclcd.Frame
+	if f, err = s.LCD.RenderFrame(text); err != nil {
+		return
+	}
+	if err = s.LCD.DrawFrame(nil, &f); err != nil {
+		return
+	}
+	s.shown = f
+	return
+}
+
+func (s *Server) handleBacklight(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		methodNotAllowed(w, http.MethodPut)
+		return
+	}
+	var v stateJSON
+	text, err := readBody(r, &v)
+	if err != nil {
+		httpError(w, err, http.StatusBadRequest)
+		return
+	}
+	if text != "" {
+		v.State = strings.TrimSpace(text)
+	}
+	for b := smclcd.BacklightOff; b <= smclcd.BacklightOn; b++ {
+		if strings.EqualFold(v.State, b.String()) {
+			if err = s.LCD.SetBacklight(b); err != nil {
+				httpError(w, err, http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	httpError(w, errors.New("invalid state: "+v.State), http.StatusBadRequest)
+}
+
+func (s *Server) handleCursor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		methodNotAllowed(w, http.MethodPut)
+		return
+	}
+	var v stateJSON
+	text, err := readBody(r, &v)
+	if err != nil {
+		httpError(w, err, http.StatusBadRequest)
+		return
+	}
+	if text != "" {
+		v.State = strings.TrimSpace(text)
+	}
+	var state = smclcd.Cursor(255)
+	for c := smclcd.CursorOff; c <= smclcd.CursorBoth; c++ {
+		if strings.EqualFold(v.State, c.String()) {
+			state = c
+		}
+	}
+	if state == 255 && v.State != "" {
+		httpError(w, errors.New("invalid state: "+v.State), http.StatusBadRequest)
+		return
+	}
+	if v.Line != nil || v.Column != nil {
+		var y, x int
+		if v.Line != nil {
+			y = *v.Line
+		}
+		if v.Column != nil {
+			x = *v.Column
+		}
+		if err = s.LCD.MoveCursor(y, x); err != nil {
+			httpError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+	if state != 255 {
+		if err = s.LCD.SetCursor(state); err != nil {
+			httpError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var v = messageJSON{Duration: r.URL.Query().Get("duration")}
+	text, err := readBody(r, &v)
+	if err != nil {
+		httpError(w, err, http.StatusBadRequest)
+		return
+	}
+	if text == "" {
+		text = strings.Join(v.Lines, "\n")
+	}
+	var d = 5 * time.Second
+	if v.Duration != "" {
+		if d, err = time.ParseDuration(v.Duration); err != nil || d <= 0 {
+			httpError(w, errors.New("invalid duration: "+v.Duration), http.StatusBadRequest)
+			return
+		}
+	}
+	if err = s.showMessage(text, d); err != nil {
+		httpError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// showMessage displays text for d, after which the previous contents of
+// the display are restored unless they have since been replaced.
+func (s *Server) showMessage(text string, d time.Duration) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer == nil {
+		if s.saved, err = s.LCD.ReadFrame(); err != nil {
+			return
+		}
+	} else {
+		s.timer.Stop()
+	}
+	if err = s.draw(text); err != nil {
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.timer != timer {
+			return
+		}
+		s.timer = nil
+		if f, err := s.LCD.ReadFrame(); err == nil && f == s.shown {
+			s.LCD.DrawFrame(&f, &s.saved)
+		}
+	})
+	s.timer = timer
+	return
+}
+
+func (s *Server) cancelMessage() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+func (s *Server) handleKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpError(w, errors.New("streaming unsupported"), http.StatusInternalServerError)
+		return
+	}
+	keys := make(chan smclcd.Key, maxKeys)
+	s.mu.Lock()
+	s.subs[keys] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, keys)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			io.WriteString(w, ": keep-alive\n\n")
+		case key := <-keys:
+			b, _ := json.Marshal(keyJSON{Code: key.Code.String(), Event: key.Event.String()})
+			fmt.Fprintf(w, "event: key\ndata: %s\n\n", b)
+		}
+		flusher.Flush()
+	}
+}
+
+// pumpKeys delivers key events to streams, discarding them while no
+// streams are connected.
+func (s *Server) pumpKeys() {
+	for {
+		key, err := s.LCD.GetInput()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		for keys := range s.subs {
+			select {
+			case keys <- key:
+			default:
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	v, err := s.LCD.Version()
+	if err != nil {
+		httpError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if wantText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, v)
+		return
+	}
+	writeJSON(w, map[string]string{"version": v})
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openapi)
+}
+
+func (s *Server) handlePanel(w http.ResponseWriter, r *http.Request) {
+	if !s.Panel || r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(panel)
+}
+
+type panelJSON struct {
+	Lines     []string `json:"lines"`
+	Cells     []int    `json:"cells"`
+	Glyphs    [][]int  `json:"glyphs"`
+	Cursor    string   `json:"cursor"`
+	Line      int      `json:"line"`
+	Column    int      `json:"column"`
+	Backlight string   `json:"backlight"`
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	if !s.Panel {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	st, err := s.LCD.ReadState()
+	if err != nil {
+		httpError(w, err, http.StatusInternalServerError)
+		return
+	}
+	v := panelJSON{
+		Lines:     strings.Split(st.Frame.Text(), "\n"),
+		Cursor:    st.Cursor.String(),
+		Line:      st.Line,
+		Column:    st.Column,
+		Backlight: st.Backlight.String(),
+	}
+	for _, c := range st.Frame {
+		v.Cells = append(v.Cells, int(c))
+	}
+	for _, g := range st.Glyphs {
+		var rows []int
+		for _, row := range g {
+			rows = append(rows, int(row))
+		}
+		v.Glyphs = append(v.Glyphs, rows)
+	}
+	writeJSON(w, v)
+}
+
+func (s *Server) handleInject(w http.ResponseWriter, r *http.Request) {
+	if !s.Panel {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var v keyJSON
+	text, err := readBody(r, &v)
+	if err != nil {
+		httpError(w, err, http.StatusBadRequest)
+		return
+	}
+	if text != "" {
+		v.Code = strings.TrimSpace(text)
+	}
+	for code := smclcd.KeyUp; code <= smclcd.KeyCancel; code++ {
+		if strings.EqualFold(v.Code, code.String()) {
+			key := smclcd.Key{Code: code, Event: smclcd.KeyPress}
+			s.LCD.InjectKey(key)
+			time.AfterFunc(pressTime, func() {
+				key.Event = smclcd.KeyRelease
+				s.LCD.InjectKey(key)
+			})
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	httpError(w, errors.New("invalid key: "+v.Code), http.StatusBadRequest)
+}
+
+// readBody decodes a JSON request body into v. Other bodies are returned
+// as text.
+func readBody(r *http.Request, v interface{}) (string, error) {
+	b, err := io.ReadAll(io.LimitReader(r.Body, 1<<16))
+	if err != nil {
+		return "", err
+	}
+	mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediatype == "application/json" {
+		return "", json.Unmarshal(b, v)
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+func wantText(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "text" ||
+		strings.HasPrefix(r.Header.Get("Accept"), "text/plain")
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, err error, code int) {
+	http.Error(w, err.Error(), code)
+}
+
+func methodNotAllowed(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	httpError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2023 Steven Stallion <sstallion@gmail.com>
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED.  IN NO EVENT SHALL THE AUTHOR OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS
+// OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION)
+// HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY
+// OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF
+// SUCH DAMAGE.
+
+package web
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sstallion/go-smclcd"
+	"github.com/sstallion/go-smclcd/internal/fakehid"
+)
+
+func TestCrossOrigin(t *testing.T) {
+	ts := httptest.NewServer(NewServer(smclcd.New(fakehid.New(smclcd.ErrTimeout))))
+	defer ts.Close()
+
+	tests := []struct {
+		method string
+		origin string
+		want   int
+	}{
+		{http.MethodPut, "", http.StatusNoContent},
+		{http.MethodPut, ts.URL, http.StatusNoContent},
+		{http.MethodPut, "http://example.com", http.StatusForbidden},
+		{http.MethodPut, "null", http.StatusForbidden},
+		{http.MethodGet, "http://example.com", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, ts.URL+"/api/backlight", strings.NewReader("off"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "text/plain")
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s with Origin %q: status %d, want %d", tt.method, tt.origin, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	l := smclcd.New(fakehid.New(smclcd.ErrTimeout))
+	ts := httptest.NewServer(NewServer(l))
+	defer ts.Close()
+
+	// Keys pressed before a stream connects are not delivered later.
+	l.InjectKey(smclcd.Key{Code: smclcd.KeyUp, Event: smclcd.KeyPress})
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Get(ts.URL + "/api/keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	l.InjectKey(smclcd.Key{Code: smclcd.KeyEnter, Event: smclcd.KeyPress})
+
+	r := bufio.NewReader(resp.Body)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(line, "data: ") {
+			if want := `data: {"code":"Enter","event":"Press"}` + "\n"; line != want {
+				t.Errorf("got %q, want %q", line, want)
+			}
+			return
+		}
+	}
+}